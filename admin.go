@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type adminStatus struct {
+	scaleSettings
+	Paused bool   `json:"paused"`
+	Preset string `json:"preset"`
+	// RateLimit is nil until a search response had the headers.
+	RateLimit *rateLimitStatus `json:"rateLimit"`
+}
+
+type adminPresets struct {
+	Preset  string   `json:"preset"`
+	Presets []string `json:"presets"`
+}
+
+// adminPresetSwitch selects a preset. Crossfade is a duration such as "3s",
+// and the configured crossfade is used when it is missing.
+type adminPresetSwitch struct {
+	Name      string  `json:"name"`
+	Crossfade *string `json:"crossfade"`
+}
+
+// adminSettingsPatch holds the settings to change. Missing fields are kept as they are.
+type adminSettingsPatch struct {
+	Keyword   *string  `json:"keyword"`
+	Threshold *int     `json:"threshold"`
+	Easing    *string  `json:"easing"`
+	Smoothing *float64 `json:"smoothing"`
+}
+
+// registerAdminAPI adds the API to control the running scale. Every request
+// must carry token as a bearer token.
+func registerAdminAPI(mux *http.ServeMux, s *calorieScale, rl *reloader, token string) {
+	auth := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request) {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				writeJSON(rw, http.StatusUnauthorized, &apiError{Error: "unauthorized"})
+				return
+			}
+			h(rw, r)
+		}
+	}
+	status := func(rw http.ResponseWriter) {
+		writeJSON(rw, http.StatusOK, &adminStatus{
+			scaleSettings: s.Settings(),
+			Paused:        s.Paused(),
+			Preset:        rl.Preset(),
+			RateLimit:     currentRateLimit(s.Settings().Keyword),
+		})
+	}
+
+	mux.HandleFunc("/api/v1/admin/settings", auth(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			status(rw)
+		case http.MethodPatch:
+			var patch adminSettingsPatch
+			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+				writeJSON(rw, http.StatusBadRequest, &apiError{Error: err.Error()})
+				return
+			}
+
+			settings := s.Settings()
+			if patch.Keyword != nil {
+				settings.Keyword = *patch.Keyword
+			}
+			if patch.Threshold != nil {
+				settings.Threshold = *patch.Threshold
+			}
+			if patch.Easing != nil {
+				settings.Easing = *patch.Easing
+			}
+			if patch.Smoothing != nil {
+				settings.Smoothing = *patch.Smoothing
+			}
+			if err := settings.validate(); err != nil {
+				writeJSON(rw, http.StatusBadRequest, &apiError{Error: err.Error()})
+				return
+			}
+			s.SetSettings(settings)
+			status(rw)
+		default:
+			writeJSON(rw, http.StatusMethodNotAllowed, &apiError{Error: "method not allowed"})
+		}
+	}))
+
+	mux.HandleFunc("/api/v1/admin/pause", auth(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(rw, http.StatusMethodNotAllowed, &apiError{Error: "method not allowed"})
+			return
+		}
+		s.Pause()
+		status(rw)
+	}))
+
+	mux.HandleFunc("/api/v1/admin/resume", auth(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(rw, http.StatusMethodNotAllowed, &apiError{Error: "method not allowed"})
+			return
+		}
+		s.Resume()
+		status(rw)
+	}))
+
+	mux.HandleFunc("/api/v1/admin/presets", auth(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(rw, http.StatusOK, &adminPresets{Preset: rl.Preset(), Presets: rl.Presets()})
+		case http.MethodPost:
+			var sw adminPresetSwitch
+			if err := json.NewDecoder(r.Body).Decode(&sw); err != nil {
+				writeJSON(rw, http.StatusBadRequest, &apiError{Error: err.Error()})
+				return
+			}
+
+			fade := rl.Crossfade()
+			if sw.Crossfade != nil {
+				var err error
+				if fade, err = time.ParseDuration(*sw.Crossfade); err != nil {
+					writeJSON(rw, http.StatusBadRequest, &apiError{Error: err.Error()})
+					return
+				}
+			}
+			if err := rl.SwitchPreset(sw.Name, fade); err != nil {
+				writeJSON(rw, http.StatusNotFound, &apiError{Error: err.Error()})
+				return
+			}
+			status(rw)
+		default:
+			writeJSON(rw, http.StatusMethodNotAllowed, &apiError{Error: "method not allowed"})
+		}
+	}))
+}
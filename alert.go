@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	alertTimeout = 10 * time.Second
+)
+
+type AlertSinkParam struct {
+	// URLs are Slack or Discord incoming webhook URLs.
+	URLs     []string      `yaml:"urls"`
+	High     int32         `yaml:"high"`
+	Low      int32         `yaml:"low"`
+	Cooldown time.Duration `yaml:"cooldown"`
+}
+
+func defaultAlertSinkParam() *AlertSinkParam {
+	return &AlertSinkParam{
+		High:     80,
+		Low:      20,
+		Cooldown: 10 * time.Minute,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *AlertSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain AlertSinkParam
+	*p = *defaultAlertSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *AlertSinkParam) enabled() bool {
+	return len(p.URLs) != 0
+}
+
+// alertSink notifies chat webhooks when calorie rises above High or falls
+// below Low, at most once per Cooldown for each direction.
+type alertSink struct {
+	param  *AlertSinkParam
+	client *http.Client
+	last   *sample
+	sent   map[string]time.Time
+	wg     sync.WaitGroup
+}
+
+func newAlertSink(param *AlertSinkParam) (*alertSink, error) {
+	if param.High <= param.Low {
+		return nil, fmt.Errorf("alert low must be below high: %d, %d", param.Low, param.High)
+	}
+	if param.Cooldown < 0 {
+		return nil, fmt.Errorf("alert cooldown must not be negative: %s", param.Cooldown)
+	}
+	return &alertSink{
+		param:  param,
+		client: &http.Client{Timeout: alertTimeout},
+		sent:   make(map[string]time.Time),
+	}, nil
+}
+
+func (a *alertSink) Name() string {
+	return "alert"
+}
+
+func (a *alertSink) Send(s *sample) error {
+	if a.last != nil && a.last.Time.Equal(s.Time) {
+		return nil
+	}
+	prev := a.last
+	a.last = s
+	if prev == nil {
+		return nil
+	}
+
+	var text string
+	var direction string
+	switch {
+	case prev.Calorie <= a.param.High && a.param.High < s.Calorie:
+		direction = crossingRise
+		text = fmt.Sprintf(":fire: %s is heating up! calorie=%d tweets=%d", s.Keyword, s.Calorie, s.Tweets)
+	case a.param.Low <= prev.Calorie && s.Calorie < a.param.Low:
+		direction = crossingFall
+		text = fmt.Sprintf(":snowflake: %s has cooled down. calorie=%d tweets=%d", s.Keyword, s.Calorie, s.Tweets)
+	default:
+		return nil
+	}
+
+	if at, ok := a.sent[direction]; ok && time.Since(at) < a.param.Cooldown {
+		return nil
+	}
+	a.sent[direction] = time.Now()
+
+	for _, url := range a.param.URLs {
+		a.wg.Add(1)
+		go func(url string) {
+			defer a.wg.Done()
+			if err := a.notify(url, text); err != nil {
+				sinkSendFailuresCounter.WithLabelValues(a.Name()).Inc()
+				slog.Error("An error occured on notify alert", "err", err)
+			}
+		}(url)
+	}
+	return nil
+}
+
+// Close waits for in-flight notifications.
+func (a *alertSink) Close() error {
+	a.wg.Wait()
+	return nil
+}
+
+func (a *alertSink) notify(url string, text string) error {
+	// Discord takes content where Slack takes text.
+	payload := map[string]string{"text": text}
+	if strings.Contains(url, "discord.com/") || strings.Contains(url, "discordapp.com/") {
+		payload = map[string]string{"content": text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// registerAPI adds the read-only REST API on top of the given scale.
+func registerAPI(mux *http.ServeMux, s *calorieScale) {
+	mux.HandleFunc("/api/v1/calorie", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(rw, http.StatusMethodNotAllowed, &apiError{Error: "method not allowed"})
+			return
+		}
+
+		latest := s.Latest()
+		if latest == nil {
+			writeJSON(rw, http.StatusServiceUnavailable, &apiError{Error: "no calorie calculated yet"})
+			return
+		}
+		writeJSON(rw, http.StatusOK, latest)
+	})
+
+	mux.HandleFunc("/api/v1/ratelimit", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(rw, http.StatusMethodNotAllowed, &apiError{Error: "method not allowed"})
+			return
+		}
+
+		rl := currentRateLimit(s.Settings().Keyword)
+		if rl == nil {
+			writeJSON(rw, http.StatusServiceUnavailable, &apiError{Error: "no rate limit observed yet"})
+			return
+		}
+		writeJSON(rw, http.StatusOK, rl)
+	})
+
+	// The history is limited to the last since, e.g. ?since=10m, when given.
+	mux.HandleFunc("/api/v1/history", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(rw, http.StatusMethodNotAllowed, &apiError{Error: "method not allowed"})
+			return
+		}
+
+		var since time.Time
+		if v := r.URL.Query().Get("since"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				writeJSON(rw, http.StatusBadRequest, &apiError{Error: err.Error()})
+				return
+			}
+			since = time.Now().Add(-d)
+		}
+		writeJSON(rw, http.StatusOK, s.History(since))
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		slog.Warn("An error occured on write json response", "err", err)
+	}
+}
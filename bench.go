@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// benchResult is the timing of the sends to a single sink.
+type benchResult struct {
+	name      string
+	latencies []time.Duration
+	failures  int
+	lastErr   error
+}
+
+// percentile returns the latency below which p of the sends finished. latencies must be sorted.
+func (r *benchResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(r.latencies)))) - 1
+	return r.latencies[max(i, 0)]
+}
+
+// runBench drives every sink of the config with synthetic samples sweeping
+// the calorie range at the given rate, and prints the throughput, failures
+// and latency percentiles of each. It returns false if any send failed.
+func runBench(args []string) bool {
+	fs := flag.NewFlagSet(os.Args[0]+" bench", flag.ExitOnError)
+	rate := fs.Float64("rate", 100, "float flag")
+	duration := fs.Duration("duration", 10*time.Second, "duration flag")
+	c, err := parseConfig(fs, args)
+	if err != nil {
+		slog.Error("An error occured on load config", "err", err)
+		return false
+	}
+	setupLogging(&c.Log)
+	if *rate <= 0 {
+		slog.Error("An error occured on bench, rate must be positive", "rate", *rate)
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	settings, sinksParam := c.presetSettings(c.Preset)
+	entries, err := openSinks(ctx, sinksParam, nil, false, closeSinks)
+	defer closeSinks(entrySinks(entries))
+	if err != nil {
+		slog.Error("An error occured on open sinks", "err", err)
+		return false
+	}
+	sinks := entrySinks(entries)
+	if c.HTTP.Addr != "" {
+		// Serve the broadcasting sinks so that clients can connect to measure them under load.
+		mux := http.NewServeMux()
+		if c.HTTP.WebSocket {
+			ws := newWebSocketSink()
+			mux.Handle("/ws", ws)
+			sinks = append(sinks, ws)
+		}
+		if c.HTTP.SSE {
+			events := newSSESink()
+			mux.Handle("/events", events)
+			sinks = append(sinks, events)
+		}
+		go serveHTTP(ctx, c.HTTP.Addr, mux)
+	}
+
+	slog.Info("Benchmarking", "sinks", len(sinks), "rate", *rate, "duration", *duration)
+	results := make([]*benchResult, len(sinks))
+	interval := time.Duration(float64(time.Second) / *rate)
+	var wg sync.WaitGroup
+	for i, sk := range sinks {
+		results[i] = &benchResult{name: sk.Name()}
+		wg.Add(1)
+		go func(sk sink, r *benchResult) {
+			defer wg.Done()
+			benchSink(sk, r, settings.Keyword, interval, *duration)
+		}(sk, results[i])
+	}
+	wg.Wait()
+
+	ok := true
+	fmt.Printf("%-12s %8s %8s %10s %10s %10s %10s\n", "sink", "sends", "failures", "p50", "p90", "p99", "max")
+	for _, r := range results {
+		sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+		fmt.Printf("%-12s %8d %8d %10s %10s %10s %10s\n", r.name, len(r.latencies), r.failures,
+			r.percentile(0.5), r.percentile(0.9), r.percentile(0.99), r.percentile(1))
+		if r.failures > 0 {
+			ok = false
+			fmt.Printf("  last error: %+v\n", r.lastErr)
+		}
+	}
+	return ok
+}
+
+// benchSink sends to sk every interval for duration, a sample sweeping from
+// the minimum to the maximum calorie and back every second.
+func benchSink(sk sink, r *benchResult, keyword string, interval time.Duration, duration time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	start := time.Now()
+	for now := range ticker.C {
+		elapsed := now.Sub(start)
+		if elapsed >= duration {
+			return
+		}
+		phase := elapsed.Seconds() - math.Floor(elapsed.Seconds())
+		s := &sample{
+			Keyword: keyword,
+			Calorie: int32(math.Round(maxCalorie * (1 - math.Abs(2*phase-1)))),
+			Time:    now,
+		}
+
+		sendStart := time.Now()
+		err := sk.Send(s)
+		r.latencies = append(r.latencies, time.Since(sendStart))
+		if err != nil {
+			r.failures++
+			r.lastErr = err
+		}
+	}
+}
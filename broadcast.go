@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	broadcastClientBuffer = 16
+)
+
+// broadcaster fans every new sample out to subscribed clients of the push
+// style sinks.
+type broadcaster struct {
+	mu      sync.Mutex
+	clients map[chan *sample]struct{}
+	latest  *sample
+	last    time.Time
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{
+		clients: make(map[chan *sample]struct{}),
+	}
+}
+
+func (b *broadcaster) Send(s *sample) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.last.Equal(s.Time) {
+		return nil
+	}
+	b.latest = s
+	for c := range b.clients {
+		select {
+		case c <- s:
+		default:
+			// Drop the sample for a client that can't keep up instead of blocking the rest.
+		}
+	}
+	b.last = s.Time
+	return nil
+}
+
+func (b *broadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for c := range b.clients {
+		close(c)
+		delete(b.clients, c)
+	}
+	return nil
+}
+
+// subscribe returns a channel that starts with the latest sample, if any.
+func (b *broadcaster) subscribe() chan *sample {
+	c := make(chan *sample, broadcastClientBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.latest != nil {
+		c <- b.latest
+	}
+	b.clients[c] = struct{}{}
+	return c
+}
+
+func (b *broadcaster) unsubscribe(c chan *sample) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.clients[c]; ok {
+		close(c)
+		delete(b.clients, c)
+	}
+}
@@ -0,0 +1,574 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	envPrefix           = "TWITTER_CALORIE_"
+	configWatchInterval = 2 * time.Second
+)
+
+// config is everything main needs to run the scale. It is read from the
+// config file given by -config, and flags set on the command line override it.
+type config struct {
+	// Path is the config file the config was loaded from, if any.
+	Path string `yaml:"-"`
+
+	Threshold int    `yaml:"threshold"`
+	Keyword   string `yaml:"keyword"`
+	// Query builds the keyword from terms when it has any.
+	Query  queryConfig `yaml:"query"`
+	Easing string      `yaml:"easing"`
+	// Smoothing is the weight of the previous calorie, from 0 for none up to but excluding 1.
+	Smoothing float64 `yaml:"smoothing"`
+	// Preset is the preset to start with. Empty starts with the settings above.
+	Preset  string                   `yaml:"preset"`
+	Presets map[string]*presetConfig `yaml:"presets"`
+	// Crossfade is how long a preset switch blends into the new calorie unless the switch gives its own.
+	Crossfade time.Duration `yaml:"crossfade"`
+	// DryRun polls and calculates as usual but only logs what would be sent to each sink.
+	DryRun bool `yaml:"dryRun"`
+	// Interactive reads manual overrides of the calorie from stdin, each lasting OverrideDuration.
+	Interactive      bool          `yaml:"interactive"`
+	OverrideDuration time.Duration `yaml:"overrideDuration"`
+	// Dashboard draws the live calorie, history, last tweets and errors on the terminal, showing the logs below.
+	Dashboard bool `yaml:"dashboard"`
+	// Park sends ParkValue to every sink once on shutdown, e.g. to turn the lights down.
+	Park      bool  `yaml:"park"`
+	ParkValue int32 `yaml:"parkValue"`
+	// History is how long the published samples are kept in memory for clients to backfill from.
+	History time.Duration `yaml:"history"`
+	// WatchConfig reloads the config file whenever it changes. SIGHUP always reloads.
+	WatchConfig bool             `yaml:"watchConfig"`
+	Twitter     twitterConfig    `yaml:"twitter"`
+	HTTP        httpConfig       `yaml:"http"`
+	GRPC        grpcConfig       `yaml:"grpc"`
+	OSCControl  oscControlConfig `yaml:"oscControl"`
+	Schedule    scheduleConfig   `yaml:"schedule"`
+	Poll        pollConfig       `yaml:"poll"`
+	Weights     weightsConfig    `yaml:"weights"`
+	Retry       retryConfig      `yaml:"retry"`
+	Simulation  simulationConfig `yaml:"simulation"`
+	Replay      replayConfig     `yaml:"replay"`
+	Database    databaseConfig   `yaml:"database"`
+	Snapshot    snapshotConfig   `yaml:"snapshot"`
+	Log         logConfig        `yaml:"log"`
+	Tracing     tracingConfig    `yaml:"tracing"`
+	Sinks       SinksParam       `yaml:"sinks"`
+}
+
+type twitterConfig struct {
+	ClientID     string `yaml:"clientID"`
+	ClientSecret string `yaml:"clientSecret"`
+	// APIURL replaces https://api.twitter.com, e.g. with a fake-twitter server.
+	APIURL string `yaml:"apiURL"`
+}
+
+type httpConfig struct {
+	Addr      string `yaml:"addr"`
+	WebSocket bool   `yaml:"websocket"`
+	SSE       bool   `yaml:"sse"`
+	// AdminToken enables the admin API for requests bearing it.
+	AdminToken string `yaml:"adminToken"`
+	// StaleAfter is how long past the poll interval /healthz and /readyz
+	// tolerate the calculation loop and the polls not succeeding.
+	StaleAfter time.Duration `yaml:"staleAfter"`
+	// UI serves the web dashboard at /.
+	UI bool `yaml:"ui"`
+	// PprofAddr serves net/http/pprof on its own listener, which should not be exposed publicly.
+	PprofAddr string `yaml:"pprofAddr"`
+}
+
+type grpcConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+// oscControlConfig is the OSC server taking control messages such as /preset.
+type oscControlConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+// defaultConfig is used when there is no config file. It sends to the OSC
+// target on localhost like before config files existed.
+func defaultConfig() *config {
+	c := newConfig()
+	c.Sinks.OSC = append(c.Sinks.OSC, defaultOSCSinkParam())
+	c.Sinks.pad()
+	return c
+}
+
+func newConfig() *config {
+	return &config{
+		Threshold:        6,
+		Keyword:          "#youtube",
+		Easing:           defaultEasing,
+		OverrideDuration: 10 * time.Second,
+		History:          time.Hour,
+		Poll: pollConfig{
+			MinInterval: calculateInterval,
+			MaxInterval: calculateInterval,
+		},
+		Weights: weightsConfig{
+			Quote:     1,
+			SelfReply: 1,
+		},
+		Retry: retryConfig{
+			MaxAge:         time.Minute,
+			DeadLetterSize: 1 << 20,
+		},
+		Twitter: twitterConfig{
+			ClientID:     "-",
+			ClientSecret: "-",
+			APIURL:       defaultTwitterAPIURL,
+		},
+		HTTP: httpConfig{
+			StaleAfter: time.Minute,
+		},
+		Tracing: tracingConfig{
+			SampleRatio: 1,
+		},
+		Log: logConfig{
+			Level:  "info",
+			Format: logFormatText,
+		},
+		Snapshot: snapshotConfig{
+			MaxAge: 10 * time.Minute,
+		},
+		Replay: replayConfig{
+			Speed: 1,
+		},
+		Simulation: simulationConfig{
+			MinRate: 0.5,
+			MaxRate: 20,
+			Period:  time.Minute,
+		},
+	}
+}
+
+func loadConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := newConfig()
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return nil, err
+	}
+	c.Path = path
+	c.Sinks.pad()
+	return c, nil
+}
+
+// parseConfig parses the command line into a config. Values are taken from,
+// in order of precedence, the flags actually set, environment variables, the
+// secrets file given by -secrets, the config file given by -config and the defaults.
+func parseConfig(fs *flag.FlagSet, args []string) (*config, error) {
+	c := defaultConfig()
+	path := fs.String("config", "", "string flag")
+	secrets := fs.String("secrets", "", "string flag")
+	registerFlags(fs, c)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+	delete(set, "config")
+	delete(set, "secrets")
+
+	if *path != "" {
+		var err error
+		if c, err = loadConfig(*path); err != nil {
+			return nil, err
+		}
+	}
+
+	fileEnv := make(map[string]string)
+	if *secrets != "" {
+		var err error
+		if fileEnv, err = loadSecrets(*secrets); err != nil {
+			return nil, err
+		}
+	}
+
+	overrides := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	registerFlags(overrides, c)
+	var err error
+	overrides.VisitAll(func(f *flag.Flag) {
+		if _, ok := set[f.Name]; ok || err != nil {
+			return
+		}
+		value, ok, lookupErr := lookupEnv(envName(f.Name), fileEnv)
+		if lookupErr != nil {
+			err = lookupErr
+			return
+		}
+		if ok {
+			if setErr := f.Value.Set(value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %w", value, envName(f.Name), setErr)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range set {
+		// Flags of a command other than the config flags are left to the command.
+		if overrides.Lookup(name) == nil {
+			continue
+		}
+		if err := overrides.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return c, c.validate()
+}
+
+func (c *config) validate() error {
+	if !c.Query.empty() {
+		if _, err := c.Query.query(); err != nil {
+			return err
+		}
+	}
+	if err := c.scaleSettings().validate(); err != nil {
+		return err
+	}
+	if err := c.Sinks.validate(); err != nil {
+		return err
+	}
+	if _, err := c.Schedule.schedule(); err != nil {
+		return err
+	}
+	if err := c.Poll.validate(); err != nil {
+		return err
+	}
+	if err := c.Weights.validate(); err != nil {
+		return err
+	}
+	if err := c.Retry.validate(); err != nil {
+		return err
+	}
+	if err := c.Log.validate(); err != nil {
+		return err
+	}
+	if c.Replay.Speed <= 0 {
+		return fmt.Errorf("replay speed must be positive: %f", c.Replay.Speed)
+	}
+	if c.History < time.Second {
+		return fmt.Errorf("history must be at least 1s: %s", c.History)
+	}
+	return c.validatePresets()
+}
+
+func (c *config) scaleSettings() scaleSettings {
+	keyword := c.Keyword
+	if !c.Query.empty() {
+		// validate reports the error.
+		keyword, _ = c.Query.query()
+	}
+	return scaleSettings{
+		Keyword:   keyword,
+		Threshold: c.Threshold,
+		Easing:    c.Easing,
+		Smoothing: c.Smoothing,
+	}
+}
+
+// envName is the environment variable for a flag, e.g. twitterClientSecret
+// is read from TWITTER_CALORIE_TWITTER_CLIENT_SECRET.
+func envName(flagName string) string {
+	var b strings.Builder
+	b.WriteString(envPrefix)
+	runes := []rune(flagName)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// lookupEnv reads name from the environment, then from the secrets file. A
+// name with the _FILE suffix holds a path to read the value from instead,
+// like Docker and systemd credentials.
+func lookupEnv(name string, fileEnv map[string]string) (string, bool, error) {
+	for _, env := range []func(string) (string, bool){os.LookupEnv, func(k string) (string, bool) {
+		v, ok := fileEnv[k]
+		return v, ok
+	}} {
+		if v, ok := env(name); ok {
+			return v, true, nil
+		}
+		if path, ok := env(name + "_FILE"); ok {
+			v, err := os.ReadFile(path)
+			if err != nil {
+				return "", false, err
+			}
+			return strings.TrimRight(string(v), "\r\n"), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// loadSecrets reads a file of NAME=value lines using the same names as the
+// environment variables. Empty lines and lines starting with # are skipped.
+func loadSecrets(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	secrets := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid line %d in %s", n, path)
+		}
+		secrets[strings.TrimSpace(kv[0])] = strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+	}
+	return secrets, scanner.Err()
+}
+
+// registerFlags binds the flags to c. Sink flags configure the first sink of each type.
+func registerFlags(fs *flag.FlagSet, c *config) {
+	fs.IntVar(&c.Threshold, "threshold", c.Threshold, "int flag")
+	fs.StringVar(&c.Keyword, "keyword", c.Keyword, "string flag")
+	fs.Var((*stringList)(&c.Query.All), "queryAll", "string flag")
+	fs.Var((*stringList)(&c.Query.Any), "queryAny", "string flag")
+	fs.Var((*stringList)(&c.Query.Hashtags), "queryHashtags", "string flag")
+	fs.Var((*stringList)(&c.Query.Mentions), "queryMentions", "string flag")
+	fs.Var((*stringList)(&c.Query.Exclude), "queryExclude", "string flag")
+	fs.StringVar(&c.Easing, "easing", c.Easing, "string flag")
+	fs.Float64Var(&c.Smoothing, "smoothing", c.Smoothing, "float flag")
+	fs.StringVar(&c.Preset, "preset", c.Preset, "string flag")
+	fs.DurationVar(&c.Crossfade, "crossfade", c.Crossfade, "duration flag")
+	fs.Var((*int32Value)(&c.Schedule.Idle), "scheduleIdle", "int flag")
+	fs.StringVar(&c.Schedule.Timezone, "scheduleTimezone", c.Schedule.Timezone, "string flag")
+	fs.BoolVar(&c.DryRun, "dryRun", c.DryRun, "bool flag")
+	fs.BoolVar(&c.Interactive, "interactive", c.Interactive, "bool flag")
+	fs.DurationVar(&c.OverrideDuration, "overrideDuration", c.OverrideDuration, "duration flag")
+	fs.BoolVar(&c.Dashboard, "dashboard", c.Dashboard, "bool flag")
+	fs.BoolVar(&c.Park, "park", c.Park, "bool flag")
+	fs.Var((*int32Value)(&c.ParkValue), "parkValue", "int flag")
+	fs.DurationVar(&c.History, "history", c.History, "duration flag")
+	fs.DurationVar(&c.Poll.MinInterval, "pollMinInterval", c.Poll.MinInterval, "duration flag")
+	fs.DurationVar(&c.Poll.MaxInterval, "pollMaxInterval", c.Poll.MaxInterval, "duration flag")
+	fs.Float64Var(&c.Weights.Quote, "quoteWeight", c.Weights.Quote, "float flag")
+	fs.Float64Var(&c.Weights.SelfReply, "selfReplyWeight", c.Weights.SelfReply, "float flag")
+	fs.IntVar(&c.Retry.Queue, "retryQueue", c.Retry.Queue, "int flag")
+	fs.DurationVar(&c.Retry.MaxAge, "retryMaxAge", c.Retry.MaxAge, "duration flag")
+	fs.StringVar(&c.Retry.DeadLetter, "deadLetter", c.Retry.DeadLetter, "string flag")
+	fs.Int64Var(&c.Retry.DeadLetterSize, "deadLetterSize", c.Retry.DeadLetterSize, "int flag")
+	fs.BoolVar(&c.WatchConfig, "watchConfig", c.WatchConfig, "bool flag")
+	fs.StringVar(&c.Twitter.ClientID, "twitterClientID", c.Twitter.ClientID, "string flag")
+	fs.StringVar(&c.Twitter.ClientSecret, "twitterClientSecret", c.Twitter.ClientSecret, "string flag")
+	fs.StringVar(&c.Twitter.APIURL, "twitterAPIURL", c.Twitter.APIURL, "string flag")
+	fs.StringVar(&c.HTTP.Addr, "httpAddr", c.HTTP.Addr, "string flag")
+	fs.BoolVar(&c.HTTP.WebSocket, "websocket", c.HTTP.WebSocket, "bool flag")
+	fs.BoolVar(&c.HTTP.SSE, "sse", c.HTTP.SSE, "bool flag")
+	fs.StringVar(&c.HTTP.AdminToken, "adminToken", c.HTTP.AdminToken, "string flag")
+	fs.DurationVar(&c.HTTP.StaleAfter, "staleAfter", c.HTTP.StaleAfter, "duration flag")
+	fs.BoolVar(&c.HTTP.UI, "ui", c.HTTP.UI, "bool flag")
+	fs.StringVar(&c.HTTP.PprofAddr, "pprofAddr", c.HTTP.PprofAddr, "string flag")
+	fs.StringVar(&c.GRPC.Addr, "grpcAddr", c.GRPC.Addr, "string flag")
+	fs.StringVar(&c.OSCControl.Addr, "oscControlAddr", c.OSCControl.Addr, "string flag")
+	fs.StringVar(&c.Database.Path, "databasePath", c.Database.Path, "string flag")
+	fs.DurationVar(&c.Database.Retention, "databaseRetention", c.Database.Retention, "duration flag")
+	fs.StringVar(&c.Log.Level, "logLevel", c.Log.Level, "string flag")
+	fs.StringVar(&c.Log.Format, "logFormat", c.Log.Format, "string flag")
+	fs.StringVar(&c.Log.Pipeline, "logPipeline", c.Log.Pipeline, "string flag")
+	fs.StringVar(&c.Tracing.Endpoint, "tracingEndpoint", c.Tracing.Endpoint, "string flag")
+	fs.BoolVar(&c.Tracing.Insecure, "tracingInsecure", c.Tracing.Insecure, "bool flag")
+	fs.Float64Var(&c.Tracing.SampleRatio, "tracingSampleRatio", c.Tracing.SampleRatio, "float flag")
+	fs.StringVar(&c.Snapshot.Path, "snapshotPath", c.Snapshot.Path, "string flag")
+	fs.DurationVar(&c.Snapshot.MaxAge, "snapshotMaxAge", c.Snapshot.MaxAge, "duration flag")
+	fs.StringVar(&c.Replay.Path, "replayPath", c.Replay.Path, "string flag")
+	fs.Float64Var(&c.Replay.Speed, "replaySpeed", c.Replay.Speed, "float flag")
+	fs.BoolVar(&c.Replay.Loop, "replayLoop", c.Replay.Loop, "bool flag")
+	fs.StringVar(&c.Simulation.Profile, "simulationProfile", c.Simulation.Profile, "string flag")
+	fs.Float64Var(&c.Simulation.MinRate, "simulationMinRate", c.Simulation.MinRate, "float flag")
+	fs.Float64Var(&c.Simulation.MaxRate, "simulationMaxRate", c.Simulation.MaxRate, "float flag")
+	fs.DurationVar(&c.Simulation.Period, "simulationPeriod", c.Simulation.Period, "duration flag")
+	fs.Float64Var(&c.Simulation.Noise, "simulationNoise", c.Simulation.Noise, "float flag")
+	fs.StringVar(&c.Simulation.Timeline, "simulationTimeline", c.Simulation.Timeline, "string flag")
+
+	osc := c.Sinks.OSC[0]
+	fs.StringVar(&osc.Host, "oscHost", osc.Host, "string flag")
+	fs.IntVar(&osc.Port, "oscPort", osc.Port, "int flag")
+	fs.StringVar(&osc.Interface, "oscInterface", osc.Interface, "string flag")
+	fs.IntVar(&osc.TTL, "oscTTL", osc.TTL, "int flag")
+	fs.StringVar(&osc.BackupHost, "oscBackupHost", osc.BackupHost, "string flag")
+	fs.IntVar(&osc.BackupPort, "oscBackupPort", osc.BackupPort, "int flag")
+	fs.DurationVar(&osc.ProbeInterval, "oscProbeInterval", osc.ProbeInterval, "duration flag")
+	fs.DurationVar(&osc.ProbeTimeout, "oscProbeTimeout", osc.ProbeTimeout, "duration flag")
+
+	midi := c.Sinks.MIDI[0]
+	fs.StringVar(&midi.Device, "midiDevice", midi.Device, "string flag")
+	fs.IntVar(&midi.Channel, "midiChannel", midi.Channel, "int flag")
+	fs.IntVar(&midi.CC, "midiCC", midi.CC, "int flag")
+	fs.StringVar(&midi.Notes, "midiNotes", midi.Notes, "string flag")
+	fs.IntVar(&midi.BurstNote, "midiBurstNote", midi.BurstNote, "int flag")
+	fs.IntVar(&midi.BurstDelta, "midiBurstDelta", midi.BurstDelta, "int flag")
+
+	sacn := c.Sinks.SACN[0]
+	fs.IntVar(&sacn.Universe, "sacnUniverse", sacn.Universe, "int flag")
+	fs.IntVar(&sacn.Priority, "sacnPriority", sacn.Priority, "int flag")
+	fs.StringVar(&sacn.Channels, "sacnChannels", sacn.Channels, "string flag")
+	fs.StringVar(&sacn.Destination, "sacnDestination", sacn.Destination, "string flag")
+
+	wled := c.Sinks.WLED[0]
+	fs.StringVar(&wled.Host, "wledHost", wled.Host, "string flag")
+	fs.IntVar(&wled.Pixels, "wledPixels", wled.Pixels, "int flag")
+	fs.StringVar(&wled.Mode, "wledMode", wled.Mode, "string flag")
+	fs.StringVar(&wled.Color, "wledColor", wled.Color, "string flag")
+
+	mqtt := c.Sinks.MQTT[0]
+	fs.StringVar(&mqtt.Broker, "mqttBroker", mqtt.Broker, "string flag")
+	fs.StringVar(&mqtt.ClientID, "mqttClientID", mqtt.ClientID, "string flag")
+	fs.StringVar(&mqtt.Topic, "mqttTopic", mqtt.Topic, "string flag")
+	fs.IntVar(&mqtt.QoS, "mqttQoS", mqtt.QoS, "int flag")
+	fs.BoolVar(&mqtt.Retain, "mqttRetain", mqtt.Retain, "bool flag")
+	fs.StringVar(&mqtt.Username, "mqttUsername", mqtt.Username, "string flag")
+	fs.StringVar(&mqtt.Password, "mqttPassword", mqtt.Password, "string flag")
+	fs.StringVar(&mqtt.CACert, "mqttCACert", mqtt.CACert, "string flag")
+	fs.StringVar(&mqtt.ClientCert, "mqttClientCert", mqtt.ClientCert, "string flag")
+	fs.StringVar(&mqtt.ClientKey, "mqttClientKey", mqtt.ClientKey, "string flag")
+	fs.BoolVar(&mqtt.Insecure, "mqttInsecure", mqtt.Insecure, "bool flag")
+	fs.StringVar(&mqtt.Encoding, "mqttEncoding", mqtt.Encoding, "string flag")
+	fs.BoolVar(&mqtt.HomeAssistant.Discovery, "haDiscovery", mqtt.HomeAssistant.Discovery, "bool flag")
+	fs.StringVar(&mqtt.HomeAssistant.Prefix, "haPrefix", mqtt.HomeAssistant.Prefix, "string flag")
+	fs.StringVar(&mqtt.HomeAssistant.NodeID, "haNodeID", mqtt.HomeAssistant.NodeID, "string flag")
+
+	remoteWrite := c.Sinks.RemoteWrite[0]
+	fs.StringVar(&remoteWrite.URL, "remoteWriteURL", remoteWrite.URL, "string flag")
+	fs.StringVar(&remoteWrite.Username, "remoteWriteUsername", remoteWrite.Username, "string flag")
+	fs.StringVar(&remoteWrite.Password, "remoteWritePassword", remoteWrite.Password, "string flag")
+	fs.DurationVar(&remoteWrite.Interval, "remoteWriteInterval", remoteWrite.Interval, "duration flag")
+	fs.IntVar(&remoteWrite.Retries, "remoteWriteRetries", remoteWrite.Retries, "int flag")
+	fs.StringVar(&remoteWrite.Instance, "remoteWriteInstance", remoteWrite.Instance, "string flag")
+
+	kafka := c.Sinks.Kafka[0]
+	fs.Var((*stringList)(&kafka.Brokers), "kafkaBrokers", "string flag")
+	fs.StringVar(&kafka.Topic, "kafkaTopic", kafka.Topic, "string flag")
+	fs.StringVar(&kafka.Encoding, "kafkaEncoding", kafka.Encoding, "string flag")
+
+	nats := c.Sinks.NATS[0]
+	fs.StringVar(&nats.URL, "natsURL", nats.URL, "string flag")
+	fs.StringVar(&nats.Subject, "natsSubject", nats.Subject, "string flag")
+	fs.StringVar(&nats.Credentials, "natsCredentials", nats.Credentials, "string flag")
+	fs.BoolVar(&nats.JetStream, "natsJetStream", nats.JetStream, "bool flag")
+	fs.StringVar(&nats.Encoding, "natsEncoding", nats.Encoding, "string flag")
+
+	redis := c.Sinks.Redis[0]
+	fs.StringVar(&redis.URL, "redisURL", redis.URL, "string flag")
+	fs.StringVar(&redis.Channel, "redisChannel", redis.Channel, "string flag")
+	fs.StringVar(&redis.Key, "redisKey", redis.Key, "string flag")
+	fs.DurationVar(&redis.TTL, "redisTTL", redis.TTL, "duration flag")
+
+	serial := c.Sinks.Serial[0]
+	fs.StringVar(&serial.Device, "serialDevice", serial.Device, "string flag")
+	fs.IntVar(&serial.Baud, "serialBaud", serial.Baud, "int flag")
+	fs.StringVar(&serial.Format, "serialFormat", serial.Format, "string flag")
+
+	gpio := c.Sinks.GPIO[0]
+	fs.StringVar(&gpio.PWMPin, "gpioPWMPin", gpio.PWMPin, "string flag")
+	fs.IntVar(&gpio.PWMFrequency, "gpioPWMFrequency", gpio.PWMFrequency, "int flag")
+	fs.Var((*stringList)(&gpio.BarPins), "gpioBarPins", "string flag")
+
+	hue := c.Sinks.Hue[0]
+	fs.StringVar(&hue.Bridge, "hueBridge", hue.Bridge, "string flag")
+	fs.StringVar(&hue.Username, "hueUsername", hue.Username, "string flag")
+	fs.Var((*stringList)(&hue.Lights), "hueLights", "string flag")
+	fs.StringVar(&hue.EntertainmentID, "hueEntertainmentID", hue.EntertainmentID, "string flag")
+	fs.StringVar(&hue.ClientKey, "hueClientKey", hue.ClientKey, "string flag")
+
+	link := c.Sinks.Link[0]
+	fs.StringVar(&link.Addr, "linkAddr", link.Addr, "string flag")
+	fs.Float64Var(&link.MinBPM, "linkMinBPM", link.MinBPM, "float flag")
+	fs.Float64Var(&link.MaxBPM, "linkMaxBPM", link.MaxBPM, "float flag")
+
+	tone := c.Sinks.Tone[0]
+	fs.StringVar(&tone.Command, "toneCommand", tone.Command, "string flag")
+	fs.Float64Var(&tone.MinFreq, "toneMinFreq", tone.MinFreq, "float flag")
+	fs.Float64Var(&tone.MaxFreq, "toneMaxFreq", tone.MaxFreq, "float flag")
+	fs.Float64Var(&tone.Volume, "toneVolume", tone.Volume, "float flag")
+
+	webhook := c.Sinks.Webhook[0]
+	fs.Var((*stringList)(&webhook.URLs), "webhookURLs", "string flag")
+	fs.StringVar(&webhook.Mode, "webhookMode", webhook.Mode, "string flag")
+	fs.StringVar(&webhook.Thresholds, "webhookThresholds", webhook.Thresholds, "string flag")
+	fs.StringVar(&webhook.Template, "webhookTemplate", webhook.Template, "string flag")
+	fs.StringVar(&webhook.Secret, "webhookSecret", webhook.Secret, "string flag")
+	fs.IntVar(&webhook.Retries, "webhookRetries", webhook.Retries, "int flag")
+
+	recorder := c.Sinks.Recorder[0]
+	fs.StringVar(&recorder.Path, "recordPath", recorder.Path, "string flag")
+	fs.StringVar(&recorder.Format, "recordFormat", recorder.Format, "string flag")
+	fs.Int64Var(&recorder.RotateSize, "recordRotateSize", recorder.RotateSize, "int flag")
+	fs.DurationVar(&recorder.RotateInterval, "recordRotateEvery", recorder.RotateInterval, "duration flag")
+
+	alert := c.Sinks.Alert[0]
+	fs.Var((*stringList)(&alert.URLs), "alertURLs", "string flag")
+	fs.Var((*int32Value)(&alert.High), "alertHigh", "int flag")
+	fs.Var((*int32Value)(&alert.Low), "alertLow", "int flag")
+	fs.DurationVar(&alert.Cooldown, "alertCooldown", alert.Cooldown, "duration flag")
+}
+
+// stringList is a comma separated flag value.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = splitList(v)
+	return nil
+}
+
+type int32Value int32
+
+func (i *int32Value) String() string {
+	return strconv.Itoa(int(*i))
+}
+
+func (i *int32Value) Set(v string) error {
+	n, err := strconv.ParseInt(v, 10, 32)
+	*i = int32Value(n)
+	return err
+}
+
+// splitList splits a comma separated value, ignoring empty entries.
+func splitList(v string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dashboardInterval = time.Second
+	dashboardWidth    = 80
+	// dashboardHistory is the number of sent calories in the sparkline, one per redraw.
+	dashboardHistory = 60
+	dashboardLogs    = 5
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// logLines keeps the last lines written to it, for the dashboard to show the
+// logs that would otherwise scroll it away.
+type logLines struct {
+	mu    sync.Mutex
+	max   int
+	lines []string
+}
+
+func newLogLines(max int) *logLines {
+	return &logLines{max: max}
+}
+
+func (l *logLines) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		l.lines = append(l.lines, string(line))
+	}
+	if len(l.lines) > l.max {
+		l.lines = l.lines[len(l.lines)-l.max:]
+	}
+	return len(p), nil
+}
+
+func (l *logLines) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
+
+// dashboard draws the live state of the scale on a terminal.
+type dashboard struct {
+	w       io.Writer
+	scale   *calorieScale
+	logs    *logLines
+	history []int32
+}
+
+func newDashboard(w io.Writer, s *calorieScale, logs *logLines) *dashboard {
+	return &dashboard{
+		w:     w,
+		scale: s,
+		logs:  logs,
+	}
+}
+
+// Run redraws the dashboard every second until ctx is done.
+func (d *dashboard) Run(ctx context.Context) {
+	ticker := time.NewTicker(dashboardInterval)
+	defer ticker.Stop()
+	// Use the alternate screen so the terminal is left as it was.
+	fmt.Fprint(d.w, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(d.w, "\x1b[?25h\x1b[?1049l")
+	for {
+		d.draw(time.Now())
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *dashboard) draw(now time.Time) {
+	s := d.scale
+	settings := s.Settings()
+	out := s.output()
+	if !s.Active(now) {
+		s.mu.RLock()
+		out = &sample{Keyword: settings.Keyword, Calorie: s.schedule.idle, Time: now}
+		s.mu.RUnlock()
+	}
+	if out != nil {
+		d.history = append(d.history, out.Calorie)
+		if len(d.history) > dashboardHistory {
+			d.history = d.history[1:]
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "twitter-calorie  keyword %s  easing %s  threshold %d  %s\n\n",
+		settings.Keyword, settings.Easing, settings.Threshold, d.state(now))
+
+	if out == nil {
+		b.WriteString("calorie   waiting for the first sample\n")
+	} else {
+		fmt.Fprintf(&b, "calorie   %s %3d\n", gauge(out.Calorie, dashboardWidth-14), out.Calorie)
+		fmt.Fprintf(&b, "tweets    %d, %.2fs apart on average, polled every %s\n", out.Tweets, out.AvgInterval, s.PollInterval())
+	}
+	fmt.Fprintf(&b, "history   %s\n", sparkline(d.history))
+	b.WriteString("rate      ")
+	if rl := currentRateLimit(settings.Keyword); rl == nil {
+		b.WriteString("unknown\n")
+	} else {
+		fmt.Fprintf(&b, "%d/%d remaining, %d projected, resets in %s", rl.Remaining, rl.Limit, rl.Projected, rl.Reset.Sub(now).Truncate(time.Second))
+		if rl.exhausts() {
+			b.WriteString("  EXHAUSTING")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nerrors\n")
+	errs := d.errors()
+	if len(errs) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, e := range errs {
+		fmt.Fprintf(&b, "  %s\n", truncate(e, dashboardWidth-2))
+	}
+
+	b.WriteString("\nlast tweets\n")
+	for _, tw := range s.RecentTweets() {
+		line := fmt.Sprintf("%s @%s %s", tw.Time.Local().Format("15:04:05"), tw.User, strings.Join(strings.Fields(tw.Text), " "))
+		fmt.Fprintf(&b, "  %s\n", truncate(line, dashboardWidth-2))
+	}
+
+	if d.logs != nil {
+		b.WriteString("\nlogs\n")
+		for _, line := range d.logs.Lines() {
+			fmt.Fprintf(&b, "  %s\n", truncate(line, dashboardWidth-2))
+		}
+	}
+	fmt.Fprint(d.w, b.String())
+}
+
+func (d *dashboard) state(now time.Time) string {
+	s := d.scale
+	switch {
+	case s.Paused():
+		return "PAUSED"
+	case !s.Active(now):
+		return "IDLE"
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.override != nil && now.Before(s.override.until) {
+		return "OVERRIDE"
+	}
+	return "LIVE"
+}
+
+// errors lists the last poll error and the sinks whose last send failed.
+func (d *dashboard) errors() []string {
+	h := d.scale.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	errs := make([]string, 0)
+	if h.pollErr != nil {
+		errs = append(errs, "poll: "+h.pollErr.Error())
+	}
+	names := make([]string, 0, len(h.sinkErrs))
+	for name, err := range h.sinkErrs {
+		if err != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, name+": "+h.sinkErrs[name].Error())
+	}
+	return errs
+}
+
+// gauge renders calorie as a bar width cells wide.
+func gauge(calorie int32, width int) string {
+	filled := int(min(max(calorie, minCalorie), maxCalorie)) * width / maxCalorie
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+func sparkline(values []int32) string {
+	var b strings.Builder
+	for _, v := range values {
+		i := int(min(max(v, minCalorie), maxCalorie)) * (len(sparkBlocks) - 1) / maxCalorie
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
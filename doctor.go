@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	doctorTimeout = 30 * time.Second
+)
+
+// doctor checks the setup before going live and prints a line per check.
+type doctor struct {
+	failed bool
+}
+
+func (d *doctor) ok(check string, format string, args ...interface{}) {
+	fmt.Printf("ok   %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) fail(check string, err error) {
+	d.failed = true
+	fmt.Printf("FAIL %s: %+v\n", check, err)
+}
+
+// runDoctor validates the config given by args, the Twitter credentials and
+// that every enabled sink of the starting preset opens. The sinks only get a
+// test sample with zero calorie with -sendTest, as it reaches the live
+// outputs. It returns false if any check failed.
+func runDoctor(args []string) bool {
+	d := &doctor{}
+	fs := flag.NewFlagSet(os.Args[0]+" doctor", flag.ExitOnError)
+	sendTest := fs.Bool("sendTest", false, "bool flag")
+	c, err := parseConfig(fs, args)
+	if err != nil {
+		d.fail("config", err)
+		return false
+	}
+	d.ok("config", "path=%s", c.Path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	settings, sinksParam := c.presetSettings(c.Preset)
+	if c.Simulation.enabled() {
+		if _, err := newSimulationSource(&c.Simulation); err != nil {
+			d.fail("simulation", err)
+		} else {
+			d.ok("simulation", "profile=%s, twitter skipped", c.Simulation.Profile)
+		}
+	} else {
+		d.checkTwitter(ctx, c, settings.Keyword)
+	}
+	d.checkSinks(ctx, sinksParam, settings.Keyword, *sendTest)
+	return !d.failed
+}
+
+func (d *doctor) checkTwitter(ctx context.Context, c *config, keyword string) {
+	client, err := newTwitterClient(ctx, &c.Twitter)
+	if err != nil {
+		d.fail("twitter", err)
+		return
+	}
+	src := newTwitterSource(client)
+	// Project the requests at the min interval, which polling never goes below.
+	setProjectedPollInterval(c.Poll.MinInterval / time.Duration(src.pollRequests(keyword)))
+	tweets, err := src.Recent(ctx, keyword)
+	if err != nil {
+		d.fail("twitter", err)
+		return
+	}
+	d.ok("twitter", "url=%s keyword=%s tweets=%d", c.Twitter.APIURL, keyword, len(tweets))
+
+	rl := currentRateLimit(keyword)
+	if rl == nil {
+		d.fail("rate limit", fmt.Errorf("no rate limit headers in response"))
+		return
+	}
+	// Polling must not run out of requests before the window resets.
+	if rl.exhausts() {
+		d.fail("rate limit", fmt.Errorf("polling needs %d requests until the reset but %d are left", rl.Projected, rl.Remaining))
+		return
+	}
+	d.ok("rate limit", "remaining=%d/%d reset in %s", rl.Remaining, rl.Limit, time.Until(rl.Reset).Round(time.Second))
+}
+
+func (d *doctor) checkSinks(ctx context.Context, p *SinksParam, keyword string, sendTest bool) {
+	for _, osc := range p.OSC {
+		if !osc.enabled() {
+			continue
+		}
+		addrs, err := net.DefaultResolver.LookupHost(ctx, osc.Host)
+		if err != nil {
+			d.fail("osc resolve", err)
+			continue
+		}
+		d.ok("osc resolve", "host=%s addrs=%v", osc.Host, addrs)
+		if osc.BackupHost == "" {
+			continue
+		}
+		addrs, err = net.DefaultResolver.LookupHost(ctx, osc.BackupHost)
+		if err != nil {
+			d.fail("osc resolve", err)
+			continue
+		}
+		d.ok("osc resolve", "host=%s addrs=%v", osc.BackupHost, addrs)
+	}
+
+	entries, err := openSinks(ctx, p, nil, false, closeSinks)
+	defer closeSinks(entrySinks(entries))
+	if err != nil {
+		for _, err := range unwrapJoined(err) {
+			d.fail("sink open", err)
+		}
+	}
+
+	test := &sample{Keyword: keyword, Time: time.Now()}
+	for _, sk := range entrySinks(entries) {
+		if !sendTest {
+			d.ok(sk.Name(), "opened, no test sample sent")
+			continue
+		}
+		if err := sk.Send(test); err != nil {
+			d.fail(sk.Name(), err)
+			continue
+		}
+		d.ok(sk.Name(), "test sample sent")
+	}
+}
+
+// unwrapJoined splits an error made by errors.Join.
+func unwrapJoined(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
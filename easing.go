@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+)
+
+const (
+	defaultEasing = "cubic"
+)
+
+// easings are the curves mapping the normalized tweet rate to calorie.
+var easings = map[string]func(float64) float64{
+	"linear": easeLinear,
+	"quad":   easeInOutQuad,
+	"cubic":  easeInOutCubic,
+	"sine":   easeInOutSine,
+}
+
+func easeLinear(x float64) float64 {
+	return x
+}
+
+func easeInOutQuad(x float64) float64 {
+	if x < .5 {
+		return 2 * x * x
+	} else {
+		return 1 - math.Pow(-2*x+2, 2)/2
+	}
+}
+
+func easeInOutCubic(x float64) float64 {
+	if x < .5 {
+		return 4 * x * x * x
+	} else {
+		return (x-1)*(2*x-2)*(2*x-2) + 1
+	}
+}
+
+func easeInOutSine(x float64) float64 {
+	return -(math.Cos(math.Pi*x) - 1) / 2
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+const (
+	fakeRateLimit       = 450
+	fakeRateLimitWindow = 15 * time.Minute
+)
+
+// fakeTwitter serves the parts of the Twitter API the scale uses: the app
+// token, the search, and the list and user timelines. Searches answer the
+// canned responses in turn, or tweets generated by a simulation when there
+// are none, and the timelines always answer simulated tweets. Credentials
+// are not checked.
+type fakeTwitter struct {
+	mu        sync.Mutex
+	canned    []*twitter.Search
+	next      int
+	sim       *simulationSource
+	lastID    int64
+	remaining int
+	reset     time.Time
+}
+
+func newFakeTwitter(canned []*twitter.Search, sim *simulationSource) *fakeTwitter {
+	return &fakeTwitter{
+		canned: canned,
+		sim:    sim,
+	}
+}
+
+func (f *fakeTwitter) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/oauth2/token":
+		writeJSON(rw, http.StatusOK, map[string]string{
+			"token_type":   "bearer",
+			"access_token": "fake",
+		})
+	case "/1.1/search/tweets.json":
+		f.search(rw, r)
+	case "/1.1/lists/statuses.json":
+		f.timeline(rw, r, "list "+r.URL.Query().Get("list_id")+r.URL.Query().Get("slug"))
+	case "/1.1/statuses/user_timeline.json":
+		f.timeline(rw, r, r.URL.Query().Get("screen_name"))
+	default:
+		writeJSON(rw, http.StatusNotFound, &apiError{Error: "not found"})
+	}
+}
+
+// limit counts a request against the rate limit and sets its headers. It
+// answers the error and returns false once the limit is exhausted. f.mu must be held.
+func (f *fakeTwitter) limit(rw http.ResponseWriter) bool {
+	now := time.Now()
+	if now.After(f.reset) {
+		f.remaining = fakeRateLimit
+		f.reset = now.Add(fakeRateLimitWindow)
+	}
+	rw.Header().Set("x-rate-limit-limit", strconv.Itoa(fakeRateLimit))
+	rw.Header().Set("x-rate-limit-reset", strconv.FormatInt(f.reset.Unix(), 10))
+	if f.remaining == 0 {
+		rw.Header().Set("x-rate-limit-remaining", "0")
+		writeJSON(rw, http.StatusTooManyRequests, map[string]interface{}{
+			"errors": []map[string]interface{}{{"code": 88, "message": "Rate limit exceeded"}},
+		})
+		return false
+	}
+	f.remaining--
+	rw.Header().Set("x-rate-limit-remaining", strconv.Itoa(f.remaining))
+	return true
+}
+
+func (f *fakeTwitter) search(rw http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.limit(rw) {
+		return
+	}
+	if len(f.canned) > 0 {
+		writeJSON(rw, http.StatusOK, f.canned[f.next])
+		f.next = (f.next + 1) % len(f.canned)
+		return
+	}
+	writeJSON(rw, http.StatusOK, &twitter.Search{Statuses: f.simulate(r, r.URL.Query().Get("q"))})
+}
+
+func (f *fakeTwitter) timeline(rw http.ResponseWriter, r *http.Request, label string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.limit(rw) {
+		return
+	}
+	writeJSON(rw, http.StatusOK, f.simulate(r, label))
+}
+
+// simulate generates the count requested of tweets, newest first. f.mu must be held.
+func (f *fakeTwitter) simulate(r *http.Request, label string) []twitter.Tweet {
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count <= 0 || sourceCount < count {
+		count = sourceCount
+	}
+	tweets, _ := f.sim.Recent(r.Context(), label)
+	statuses := make([]twitter.Tweet, 0, count)
+	for i, tw := range tweets[:count] {
+		statuses = append(statuses, twitter.Tweet{
+			ID:        f.lastID + int64(count-i),
+			CreatedAt: tw.Time.UTC().Format(time.RubyDate),
+			Text:      fmt.Sprintf("fake tweet %s", label),
+			User:      &twitter.User{ScreenName: "fake"},
+		})
+	}
+	f.lastID += int64(count)
+	return statuses
+}
+
+// loadCannedSearches reads a JSON array of search responses.
+func loadCannedSearches(path string) ([]*twitter.Search, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	canned := make([]*twitter.Search, 0)
+	if err := json.Unmarshal(b, &canned); err != nil {
+		return nil, err
+	}
+	if len(canned) == 0 {
+		return nil, fmt.Errorf("no responses in %s", path)
+	}
+	return canned, nil
+}
+
+// runFakeTwitter serves a fake Twitter API for development and integration
+// tests, to be used with -twitterAPIURL. It returns false if it failed.
+func runFakeTwitter(args []string) bool {
+	fs := flag.NewFlagSet(os.Args[0]+" fake-twitter", flag.ExitOnError)
+	addr := fs.String("addr", ":8081", "string flag")
+	responses := fs.String("responses", "", "string flag")
+	sim := &simulationConfig{
+		Profile: simulationSine,
+		MinRate: 0.5,
+		MaxRate: 20,
+		Period:  time.Minute,
+	}
+	fs.StringVar(&sim.Profile, "profile", sim.Profile, "string flag")
+	fs.Float64Var(&sim.MinRate, "minRate", sim.MinRate, "float flag")
+	fs.Float64Var(&sim.MaxRate, "maxRate", sim.MaxRate, "float flag")
+	fs.DurationVar(&sim.Period, "period", sim.Period, "duration flag")
+	fs.Float64Var(&sim.Noise, "noise", sim.Noise, "float flag")
+	fs.StringVar(&sim.Timeline, "timeline", sim.Timeline, "string flag")
+	fs.Parse(args)
+
+	var canned []*twitter.Search
+	if *responses != "" {
+		var err error
+		if canned, err = loadCannedSearches(*responses); err != nil {
+			slog.Error("An error occured on load responses", "err", err)
+			return false
+		}
+	}
+	source, err := newSimulationSource(sim)
+	if err != nil {
+		slog.Error("An error occured on open simulation", "err", err)
+		return false
+	}
+
+	slog.Info("Listening fake twitter", "addr", *addr)
+	if err := http.ListenAndServe(*addr, newFakeTwitter(canned, source)); err != nil {
+		slog.Error("An error occured on fake twitter server", "err", err)
+		return false
+	}
+	return true
+}
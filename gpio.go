@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"go.yaml.in/yaml/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/host/v3"
+)
+
+type GPIOSinkParam struct {
+	// PWMPin drives a duty cycle proportional to calorie, e.g. "GPIO18". Empty disables it.
+	PWMPin string `yaml:"pwmPin"`
+	// PWMFrequency is in Hz.
+	PWMFrequency int `yaml:"pwmFrequency"`
+	// BarPins are lit from the first one up as calorie rises, like an LED bar.
+	BarPins []string `yaml:"barPins"`
+}
+
+func defaultGPIOSinkParam() *GPIOSinkParam {
+	return &GPIOSinkParam{
+		PWMFrequency: 1000,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *GPIOSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain GPIOSinkParam
+	*p = *defaultGPIOSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *GPIOSinkParam) enabled() bool {
+	return p.PWMPin != "" || len(p.BarPins) != 0
+}
+
+// gpioSink drives pins on the machine running the binary such as a
+// Raspberry Pi, for meters, fans or LED bars.
+type gpioSink struct {
+	pwm       gpio.PinIO
+	frequency physic.Frequency
+	// duty is the last duty cycle written, to reprogram the PWM only on change.
+	duty gpio.Duty
+	bar  []gpio.PinIO
+}
+
+func newGPIOSink(param *GPIOSinkParam) (*gpioSink, error) {
+	if param.PWMPin != "" && param.PWMFrequency <= 0 {
+		return nil, fmt.Errorf("gpio pwm frequency must be positive: %d", param.PWMFrequency)
+	}
+	if _, err := host.Init(); err != nil {
+		return nil, err
+	}
+
+	g := &gpioSink{
+		frequency: physic.Frequency(param.PWMFrequency) * physic.Hertz,
+		duty:      -1,
+		bar:       make([]gpio.PinIO, 0, len(param.BarPins)),
+	}
+	if param.PWMPin != "" {
+		if g.pwm = gpioreg.ByName(param.PWMPin); g.pwm == nil {
+			return nil, fmt.Errorf("unknown gpio pin: %s", param.PWMPin)
+		}
+	}
+	for _, name := range param.BarPins {
+		pin := gpioreg.ByName(name)
+		if pin == nil {
+			return nil, fmt.Errorf("unknown gpio pin: %s", name)
+		}
+		g.bar = append(g.bar, pin)
+	}
+	return g, nil
+}
+
+func (g *gpioSink) Name() string {
+	return "gpio"
+}
+
+func (g *gpioSink) Send(s *sample) error {
+	level := math.Max(0, math.Min(1, float64(s.Calorie)/100))
+
+	if duty := gpio.Duty(level * float64(gpio.DutyMax)); g.pwm != nil && duty != g.duty {
+		if err := g.pwm.PWM(duty, g.frequency); err != nil {
+			return err
+		}
+		g.duty = duty
+	}
+
+	lit := int(math.Round(level * float64(len(g.bar))))
+	for i, pin := range g.bar {
+		if err := pin.Out(i < lit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close leaves every pin low so nothing stays driven after exit.
+func (g *gpioSink) Close() error {
+	if g.pwm != nil {
+		g.pwm.Halt()
+		g.pwm.Out(gpio.Low)
+	}
+	for _, pin := range g.bar {
+		pin.Out(gpio.Low)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	caloriev1 "github.com/miyukki/twitter-calorie/proto/calorie/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcSink serves CalorieService and streams every new sample to subscribers.
+type grpcSink struct {
+	caloriev1.UnimplementedCalorieServiceServer
+	*broadcaster
+	server *grpc.Server
+	scale  *calorieScale
+}
+
+func newGRPCSink() *grpcSink {
+	g := &grpcSink{
+		broadcaster: newBroadcaster(),
+		server:      grpc.NewServer(),
+	}
+	caloriev1.RegisterCalorieServiceServer(g.server, g)
+	return g
+}
+
+func (g *grpcSink) Name() string {
+	return "grpc"
+}
+
+// Serve listens on addr until ctx is done, answering unary getters from scale.
+func (g *grpcSink) Serve(ctx context.Context, addr string, scale *calorieScale) {
+	g.scale = scale
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		slog.Error("An error occured on listen grpc", "err", err)
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		g.broadcaster.Close()
+		g.server.GracefulStop()
+	}()
+
+	slog.Info("Listening grpc", "addr", addr)
+	if err := g.server.Serve(lis); err != nil {
+		slog.Error("An error occured on grpc server", "err", err)
+	}
+}
+
+func (g *grpcSink) Close() error {
+	g.broadcaster.Close()
+	g.server.Stop()
+	return nil
+}
+
+func (g *grpcSink) GetLatest(ctx context.Context, req *caloriev1.GetLatestRequest) (*caloriev1.Sample, error) {
+	latest := g.scale.Latest()
+	if latest == nil {
+		return nil, status.Error(codes.Unavailable, "no calorie calculated yet")
+	}
+	return toProtoSample(latest), nil
+}
+
+func (g *grpcSink) GetStatus(ctx context.Context, req *caloriev1.GetStatusRequest) (*caloriev1.Status, error) {
+	sinks := make([]string, 0)
+	for _, sk := range g.scale.Sinks() {
+		sinks = append(sinks, sk.Name())
+	}
+
+	settings := g.scale.Settings()
+	st := &caloriev1.Status{
+		Keyword:   settings.Keyword,
+		Threshold: int32(settings.Threshold),
+		Sinks:     sinks,
+	}
+	if latest := g.scale.Latest(); latest != nil {
+		st.Latest = toProtoSample(latest)
+	}
+	return st, nil
+}
+
+func (g *grpcSink) Subscribe(req *caloriev1.SubscribeRequest, stream caloriev1.CalorieService_SubscribeServer) error {
+	c := g.subscribe()
+	defer g.unsubscribe(c)
+
+	for {
+		select {
+		case s, ok := <-c:
+			if !ok {
+				return status.Error(codes.Unavailable, "shutting down")
+			}
+			if err := stream.Send(toProtoSample(s)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
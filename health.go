@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"golang.org/x/oauth2"
+)
+
+// Twitter error codes of a bad token.
+var twitterAuthErrorCodes = map[int]bool{
+	32:  true,
+	89:  true,
+	215: true,
+}
+
+// health tracks the outcome of the polls and sends for the health endpoints.
+type health struct {
+	mu sync.Mutex
+	// tick is when the calculation loop last ran, even if it didn't poll.
+	tick     time.Time
+	polled   time.Time
+	pollErr  error
+	sinkErrs map[string]error
+}
+
+func newHealth() *health {
+	return &health{
+		tick:     time.Now(),
+		sinkErrs: make(map[string]error),
+	}
+}
+
+func (h *health) observeTick() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tick = time.Now()
+}
+
+func (h *health) observePoll(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pollErr = err
+	if err == nil {
+		h.polled = time.Now()
+	}
+}
+
+func (h *health) observeSend(sink string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sinkErrs[sink] = err
+}
+
+// isAuthError reports whether err is caused by the Twitter credentials.
+func isAuthError(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return true
+	}
+	var apiErr twitter.APIError
+	if errors.As(err, &apiErr) {
+		for _, e := range apiErr.Errors {
+			if twitterAuthErrorCodes[e.Code] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkLoop fails when the calculation loop hasn't run for staleAfter past
+// the poll interval, which may be long while waiting out the rate limit.
+func (s *calorieScale) checkLoop(staleAfter time.Duration) error {
+	stale := s.PollInterval() + staleAfter
+	h := s.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s.source != nil && time.Since(h.tick) > stale {
+		return errors.New("calculation loop stalled since " + h.tick.Format(time.RFC3339))
+	}
+	return nil
+}
+
+type healthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (r *healthReport) check(name string, err error) {
+	if err != nil {
+		r.Status = "fail"
+		r.Checks[name] = err.Error()
+		return
+	}
+	r.Checks[name] = "ok"
+}
+
+func newHealthReport() *healthReport {
+	return &healthReport{Status: "ok", Checks: make(map[string]string)}
+}
+
+func writeHealthReport(rw http.ResponseWriter, r *healthReport) {
+	status := http.StatusOK
+	if r.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(rw, status, r)
+}
+
+// registerHealth adds /healthz, failing when the calculation loop stopped
+// running for staleAfter past the poll interval, and /readyz, failing when
+// the Twitter token is rejected, no poll succeeded for staleAfter past the
+// poll interval while polling, or the last send to a sink failed.
+func registerHealth(mux *http.ServeMux, s *calorieScale, staleAfter time.Duration) {
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) {
+		report := newHealthReport()
+		report.check("loop", s.checkLoop(staleAfter))
+		writeHealthReport(rw, report)
+	})
+
+	mux.HandleFunc("/readyz", func(rw http.ResponseWriter, r *http.Request) {
+		polling := s.source != nil && !s.Paused() && s.Active(time.Now())
+		stale := s.PollInterval() + staleAfter
+		h := s.health
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		report := newHealthReport()
+		if s.source != nil {
+			var tokenErr error
+			if isAuthError(h.pollErr) {
+				tokenErr = h.pollErr
+			}
+			report.check("token", tokenErr)
+
+			var pollErr error
+			if polling && time.Since(h.polled) > stale {
+				switch {
+				case h.pollErr != nil:
+					pollErr = h.pollErr
+				case h.polled.IsZero():
+					pollErr = errors.New("no successful poll yet")
+				default:
+					pollErr = errors.New("no successful poll since " + h.polled.Format(time.RFC3339))
+				}
+			}
+			report.check("poll", pollErr)
+		}
+		for name, err := range h.sinkErrs {
+			report.check("sink "+name, err)
+		}
+		writeHealthReport(rw, report)
+	})
+}
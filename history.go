@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// history is a ring buffer of the samples published within a duration, for
+// clients to backfill from.
+type history struct {
+	mu       sync.Mutex
+	duration time.Duration
+	samples  []*sample
+	// next is the index the next sample is written to.
+	next int
+	full bool
+}
+
+// newHistory keeps the samples of the last d. It holds one sample per
+// second of d, which is as often as the samples are sent.
+func newHistory(d time.Duration) *history {
+	return &history{
+		duration: d,
+		samples:  make([]*sample, max(int(d/time.Second), 1)),
+	}
+}
+
+func (h *history) Add(s *sample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples[h.next] = s
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Since returns the samples of the kept duration that are newer than t, oldest first.
+func (h *history) Since(t time.Time) []*sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if oldest := time.Now().Add(-h.duration); t.Before(oldest) {
+		t = oldest
+	}
+	ordered := h.samples[:h.next]
+	if h.full {
+		ordered = append(append([]*sample(nil), h.samples[h.next:]...), ordered...)
+	}
+	samples := make([]*sample, 0, len(ordered))
+	for _, s := range ordered {
+		if s.Time.After(t) {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	haPayloadOnline  = "online"
+	haPayloadOffline = "offline"
+)
+
+type HomeAssistantParam struct {
+	Discovery bool   `yaml:"discovery"`
+	Prefix    string `yaml:"prefix"`
+	NodeID    string `yaml:"nodeID"`
+}
+
+type haDevice struct {
+	Identifiers []string `json:"identifiers"`
+	Name        string   `json:"name"`
+}
+
+type haSensorConfig struct {
+	Name                string   `json:"name"`
+	UniqueID            string   `json:"unique_id"`
+	StateTopic          string   `json:"state_topic"`
+	ValueTemplate       string   `json:"value_template"`
+	JSONAttributesTopic string   `json:"json_attributes_topic"`
+	AvailabilityTopic   string   `json:"availability_topic"`
+	UnitOfMeasurement   string   `json:"unit_of_measurement"`
+	StateClass          string   `json:"state_class"`
+	Icon                string   `json:"icon"`
+	Device              haDevice `json:"device"`
+}
+
+// haAvailabilityTopic is where online is published on connect and offline is
+// left as the last will, so the entity goes unavailable when the scale dies.
+func haAvailabilityTopic(stateTopic string) string {
+	return stateTopic + "/availability"
+}
+
+func haConfigTopic(param *HomeAssistantParam) string {
+	return fmt.Sprintf("%s/sensor/%s/calorie/config", param.Prefix, param.NodeID)
+}
+
+// haSensorConfigPayload is the discovery config announcing the calorie as a
+// sensor entity that reads its value from the regular state topic.
+func haSensorConfigPayload(param *HomeAssistantParam, stateTopic string) ([]byte, error) {
+	return json.Marshal(&haSensorConfig{
+		Name:                "Calorie",
+		UniqueID:            param.NodeID + "_calorie",
+		StateTopic:          stateTopic,
+		ValueTemplate:       "{{ value_json.calorie }}",
+		JSONAttributesTopic: stateTopic,
+		AvailabilityTopic:   haAvailabilityTopic(stateTopic),
+		UnitOfMeasurement:   "%",
+		StateClass:          "measurement",
+		Icon:                "mdi:fire",
+		Device: haDevice{
+			Identifiers: []string{param.NodeID},
+			Name:        "Twitter Calorie",
+		},
+	})
+}
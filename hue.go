@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pion/dtls/v3"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	hueTimeout          = 5 * time.Second
+	hueEntertainmentUDP = "2100"
+)
+
+type HueSinkParam struct {
+	Bridge string `yaml:"bridge"`
+	// Username is the application key registered on the bridge.
+	Username string `yaml:"username"`
+	// Lights are light ids of the v1 API, driven with REST when not streaming.
+	Lights []string `yaml:"lights"`
+	// EntertainmentID and ClientKey enable the low latency entertainment streaming API.
+	EntertainmentID string `yaml:"entertainmentID"`
+	ClientKey       string `yaml:"clientKey"`
+}
+
+func defaultHueSinkParam() *HueSinkParam {
+	return &HueSinkParam{}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *HueSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain HueSinkParam
+	*p = *defaultHueSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *HueSinkParam) enabled() bool {
+	return p.Bridge != ""
+}
+
+// hueSink maps calorie to brightness and color of Philips Hue lights, from
+// dim blue when calm to bright red when hot.
+type hueSink struct {
+	param    *HueSinkParam
+	client   *http.Client
+	stream   *dtls.Conn
+	channels []byte
+	sequence byte
+	last     int32
+}
+
+func newHueSink(param *HueSinkParam) (*hueSink, error) {
+	h := &hueSink{
+		param: param,
+		client: &http.Client{
+			Timeout: hueTimeout,
+			// Bridges serve a self-signed certificate on the local network.
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+		last: -1,
+	}
+	if param.EntertainmentID != "" {
+		if err := h.startStream(); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *hueSink) Name() string {
+	return "hue"
+}
+
+func (h *hueSink) Send(s *sample) error {
+	if h.stream != nil {
+		return h.sendStream(s.Calorie)
+	}
+
+	// The REST API only takes around ten commands per second, so skip unchanged values.
+	if h.last == s.Calorie {
+		return nil
+	}
+	level := math.Max(0, math.Min(1, float64(s.Calorie)/100))
+	state := map[string]interface{}{
+		"on":             0 < level,
+		"bri":            1 + int(level*253),
+		"hue":            int(240 * (1 - level) / 360 * 65535),
+		"sat":            254,
+		"transitiontime": 10,
+	}
+	for _, light := range h.param.Lights {
+		if err := h.request(http.MethodPut, fmt.Sprintf("/api/%s/lights/%s/state", h.param.Username, light), state, nil); err != nil {
+			return err
+		}
+	}
+	h.last = s.Calorie
+	return nil
+}
+
+func (h *hueSink) Close() error {
+	if h.stream == nil {
+		return nil
+	}
+	h.stream.Close()
+	return h.request(http.MethodPut, "/clip/v2/resource/entertainment_configuration/"+h.param.EntertainmentID,
+		map[string]string{"action": "stop"}, nil)
+}
+
+// startStream activates the entertainment configuration and opens the DTLS
+// stream, authenticating with the application id and client key.
+func (h *hueSink) startStream() (err error) {
+	psk, err := hex.DecodeString(h.param.ClientKey)
+	if err != nil {
+		return fmt.Errorf("invalid hue client key: %w", err)
+	}
+
+	var config struct {
+		Data []struct {
+			Channels []struct {
+				ChannelID byte `json:"channel_id"`
+			} `json:"channels"`
+		} `json:"data"`
+	}
+	path := "/clip/v2/resource/entertainment_configuration/" + h.param.EntertainmentID
+	if err := h.request(http.MethodGet, path, nil, &config); err != nil {
+		return err
+	}
+	if len(config.Data) == 0 {
+		return fmt.Errorf("hue entertainment configuration not found: %s", h.param.EntertainmentID)
+	}
+	for _, c := range config.Data[0].Channels {
+		h.channels = append(h.channels, c.ChannelID)
+	}
+
+	appID, err := h.applicationID()
+	if err != nil {
+		return err
+	}
+	if err := h.request(http.MethodPut, path, map[string]string{"action": "start"}, nil); err != nil {
+		return err
+	}
+	// Release the configuration when streaming fails, or the bridge keeps
+	// it reserved and other clients can't use it.
+	defer func() {
+		if err != nil {
+			h.request(http.MethodPut, path, map[string]string{"action": "stop"}, nil)
+		}
+	}()
+
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(h.param.Bridge, hueEntertainmentUDP))
+	if err != nil {
+		return err
+	}
+	conn, err := dtls.DialWithOptions("udp", addr,
+		dtls.WithPSK(func([]byte) ([]byte, error) { return psk, nil }),
+		dtls.WithPSKIdentityHint([]byte(appID)),
+		dtls.WithCipherSuites(dtls.TLS_PSK_WITH_AES_128_GCM_SHA256),
+	)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hueTimeout)
+	defer cancel()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return err
+	}
+	h.stream = conn
+	return nil
+}
+
+// sendStream writes a HueStream v2 message setting every channel to the same color.
+func (h *hueSink) sendStream(calorie int32) error {
+	level := math.Max(0, math.Min(1, float64(calorie)/100))
+	r, g, b := hueToRGB(240 * (1 - level))
+
+	msg := make([]byte, 0, 52+len(h.channels)*7)
+	msg = append(msg, "HueStream"...)
+	msg = append(msg, 0x02, 0x00, h.sequence, 0x00, 0x00, 0x00, 0x00)
+	msg = append(msg, h.param.EntertainmentID...)
+	for _, c := range h.channels {
+		msg = append(msg, c)
+		for _, v := range []byte{r, g, b} {
+			msg = binary.BigEndian.AppendUint16(msg, uint16(float64(v)*level)*257)
+		}
+	}
+	h.sequence++
+
+	_, err := h.stream.Write(msg)
+	return err
+}
+
+// applicationID looks up the id used as the PSK identity of the stream.
+func (h *hueSink) applicationID() (string, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://"+h.param.Bridge+"/auth/v1", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("hue-application-key", h.param.Username)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	appID := resp.Header.Get("hue-application-id")
+	if appID == "" {
+		return "", fmt.Errorf("hue bridge returned no application id: %s", resp.Status)
+	}
+	return appID, nil
+}
+
+func (h *hueSink) request(method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, "https://"+h.param.Bridge+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("hue-application-key", h.param.Username)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("hue bridge returned %s for %s %s", resp.Status, method, path)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	kafkaWriteTimeout = 10 * time.Second
+)
+
+type KafkaSinkParam struct {
+	Brokers []string `yaml:"brokers"`
+	Topic   string   `yaml:"topic"`
+	// Encoding of the calorie.v1.Sample value, json or protobuf.
+	Encoding string `yaml:"encoding"`
+}
+
+func defaultKafkaSinkParam() *KafkaSinkParam {
+	return &KafkaSinkParam{
+		Topic:    "twitter-calorie",
+		Encoding: sampleEncodingJSON,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *KafkaSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain KafkaSinkParam
+	*p = *defaultKafkaSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *KafkaSinkParam) enabled() bool {
+	return len(p.Brokers) != 0
+}
+
+// kafkaSink produces every new sample keyed by keyword, with its content
+// type in a header.
+type kafkaSink struct {
+	writer   *kafka.Writer
+	encoding string
+	last     time.Time
+}
+
+func newKafkaSink(param *KafkaSinkParam) (*kafkaSink, error) {
+	if err := validateSampleEncoding(param.Encoding); err != nil {
+		return nil, err
+	}
+	return &kafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(param.Brokers...),
+			Topic:        param.Topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireOne,
+			// Write each sample at once rather than waiting out the default
+			// batch timeout of a second, which would hold up the send loop.
+			BatchSize: 1,
+		},
+		encoding: param.Encoding,
+	}, nil
+}
+
+func (k *kafkaSink) Name() string {
+	return "kafka"
+}
+
+func (k *kafkaSink) Send(s *sample) error {
+	if k.last.Equal(s.Time) {
+		return nil
+	}
+	value, err := encodeSample(s, k.encoding)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaWriteTimeout)
+	defer cancel()
+	err = k.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(s.Keyword),
+		Value: value,
+		Time:  s.Time,
+		Headers: []kafka.Header{
+			{Key: "content-type", Value: []byte(sampleContentType(k.encoding))},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	k.last = s.Time
+	return nil
+}
+
+func (k *kafkaSink) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	linkDialTimeout  = 5 * time.Second
+	linkWriteTimeout = time.Second
+)
+
+type LinkSinkParam struct {
+	// Addr is a Carabiner instance, which bridges the Ableton Link session over TCP.
+	Addr   string  `yaml:"addr"`
+	MinBPM float64 `yaml:"minBPM"`
+	MaxBPM float64 `yaml:"maxBPM"`
+}
+
+func defaultLinkSinkParam() *LinkSinkParam {
+	return &LinkSinkParam{
+		MinBPM: 100,
+		MaxBPM: 140,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *LinkSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain LinkSinkParam
+	*p = *defaultLinkSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *LinkSinkParam) enabled() bool {
+	return p.Addr != ""
+}
+
+// linkSink nudges the tempo of an Ableton Link session between MinBPM and
+// MaxBPM according to calorie. Link itself has no Go implementation, so the
+// tempo goes through Carabiner (https://github.com/Deep-Symmetry/carabiner).
+type linkSink struct {
+	param *LinkSinkParam
+	conn  net.Conn
+	last  float64
+}
+
+func newLinkSink(param *LinkSinkParam) (*linkSink, error) {
+	if param.MinBPM < 20 || 999 < param.MaxBPM || param.MaxBPM < param.MinBPM {
+		return nil, fmt.Errorf("link bpm range must be within 20-999: %f-%f", param.MinBPM, param.MaxBPM)
+	}
+
+	return &linkSink{
+		param: param,
+	}, nil
+}
+
+func (l *linkSink) Name() string {
+	return "link"
+}
+
+func (l *linkSink) Send(s *sample) error {
+	level := math.Max(0, math.Min(1, float64(s.Calorie)/100))
+	bpm := math.Round((l.param.MinBPM+(l.param.MaxBPM-l.param.MinBPM)*level)*10) / 10
+	if l.conn != nil && bpm == l.last {
+		return nil
+	}
+
+	// Carabiner may be restarted during a show, so reconnect lazily.
+	if l.conn == nil {
+		conn, err := net.DialTimeout("tcp", l.param.Addr, linkDialTimeout)
+		if err != nil {
+			return err
+		}
+		l.conn = conn
+		go discard(conn)
+	}
+
+	l.conn.SetWriteDeadline(time.Now().Add(linkWriteTimeout))
+	if _, err := fmt.Fprintf(l.conn, "bpm %.1f\n", bpm); err != nil {
+		l.conn.Close()
+		l.conn = nil
+		return err
+	}
+	l.last = bpm
+	return nil
+}
+
+func (l *linkSink) Close() error {
+	if l.conn == nil {
+		return nil
+	}
+	return l.conn.Close()
+}
+
+// discard drains the status messages Carabiner sends back until the connection closes.
+func discard(conn net.Conn) {
+	buf := make([]byte, 1024)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// logOutput is where the logs are written, replaced by the dashboard while it draws on the terminal.
+var logOutput io.Writer = os.Stderr
+
+// logConfig configures the structured logs written to stderr.
+type logConfig struct {
+	// Level is debug, info, warn or error.
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+	// Pipeline is added to every record to tell apart the logs of several scales.
+	Pipeline string `yaml:"pipeline"`
+}
+
+func (c *logConfig) validate() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return fmt.Errorf("unknown log level: %s", c.Level)
+	}
+	if c.Format != logFormatText && c.Format != logFormatJSON {
+		return fmt.Errorf("unknown log format: %s", c.Format)
+	}
+	return nil
+}
+
+// setupLogging makes the configured logger the default, which the log
+// package writes through as well.
+func setupLogging(c *logConfig) {
+	var level slog.Level
+	level.UnmarshalText([]byte(c.Level))
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler = slog.NewTextHandler(logOutput, opts)
+	if c.Format == logFormatJSON {
+		handler = slog.NewJSONHandler(logOutput, opts)
+	}
+	if lw, ok := logOutput.(leveledWriter); ok {
+		handler = &leveledHandler{Handler: handler, w: lw, mu: &sync.Mutex{}}
+	}
+	logger := slog.New(handler)
+	if c.Pipeline != "" {
+		logger = logger.With("pipeline", c.Pipeline)
+	}
+	slog.SetDefault(logger)
+}
+
+// leveledWriter is a log output that treats the records differently by
+// level, such as the Windows event log.
+type leveledWriter interface {
+	io.Writer
+	// SetLevel sets the level of the record written next.
+	SetLevel(level slog.Level)
+}
+
+// leveledHandler tells w the level of each record before it is written.
+type leveledHandler struct {
+	slog.Handler
+	w  leveledWriter
+	mu *sync.Mutex
+}
+
+func (h *leveledHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.w.SetLevel(r.Level)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *leveledHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &leveledHandler{Handler: h.Handler.WithAttrs(attrs), w: h.w, mu: h.mu}
+}
+
+func (h *leveledHandler) WithGroup(name string) slog.Handler {
+	return &leveledHandler{Handler: h.Handler.WithGroup(name), w: h.w, mu: h.mu}
+}
+
+// fatal logs msg as an error and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
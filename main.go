@@ -3,152 +3,513 @@ package main
 import (
 	"context"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"math"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
-	"github.com/dghubble/go-twitter/twitter"
-	"github.com/hypebeast/go-osc/osc"
-	"golang.org/x/oauth2/clientcredentials"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	maxHistory = 50
+	maxHistory        = 50
+	maxRecentTweets   = 10
+	calculateInterval = 6 * time.Second
 )
 
 type CalorieScaleParam struct {
-	Threshold           int
-	Keyword             string
-	OSCHost             string
-	OSCPort             int
-	TwitterClientID     string
-	TwitterClientSecret string
+	Threshold int
+	Keyword   string
+	Easing    string
+	Smoothing float64
+	Sinks     []sink
+	Schedule  *schedule
+	Source    source
+	Store     *store
+	// History is how long the published samples are kept for History.
+	History time.Duration
+	Poll    pollConfig
+	Weights weightsConfig
+	// Retries queues the failed sends. Nil sends each sample once.
+	Retries *retryQueues
 }
 
-func newCalorieScale(ctx context.Context, param *CalorieScaleParam) *calorieScale {
-	oscClient := osc.NewClient(param.OSCHost, param.OSCPort)
-
-	config := &clientcredentials.Config{
-		ClientID:     param.TwitterClientID,
-		ClientSecret: param.TwitterClientSecret,
-		TokenURL:     "https://api.twitter.com/oauth2/token",
-	}
-	httpClient := config.Client(ctx)
-	twitterClient := twitter.NewClient(httpClient)
+// scaleSettings are the settings of a running scale that can be changed without restarting.
+type scaleSettings struct {
+	Keyword   string  `json:"keyword"`
+	Threshold int     `json:"threshold"`
+	Easing    string  `json:"easing"`
+	Smoothing float64 `json:"smoothing"`
+}
 
-	return &calorieScale{
+func newCalorieScale(ctx context.Context, param *CalorieScaleParam) *calorieScale {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &calorieScale{
 		ctx:             ctx,
+		cancel:          cancel,
 		threshold:       param.Threshold,
 		keyword:         param.Keyword,
+		easing:          param.Easing,
+		smoothing:       param.Smoothing,
 		calorie:         atomic.Value{},
-		twitterClient:   twitterClient,
-		oscClient:       oscClient,
-		oscInterval:     time.Second,
-		intervalHistory: make([]float64, 0),
+		source:          param.Source,
+		store:           param.Store,
+		health:          newHealth(),
+		history:         newHistory(param.History),
+		poll:            param.Poll,
+		weights:         param.Weights,
+		retries:         param.Retries,
+		sinks:           param.Sinks,
+		schedule:        param.Schedule,
+		sendInterval:    time.Second,
+		intervalHistory: param.Store.Baseline(param.Keyword, maxHistory),
+	}
+	s.pollInterval.Store(int64(param.Poll.MinInterval))
+	s.restoreHistory(param.History)
+	if s.retries != nil {
+		s.retries.observe = s.observeSend
 	}
+	return s
 }
 
 type calorieScale struct {
-	ctx             context.Context
-	threshold       int
-	keyword         string
-	calorie         atomic.Value
-	twitterClient   *twitter.Client
-	oscClient       *osc.Client
-	oscInterval     time.Duration
+	ctx    context.Context
+	cancel context.CancelFunc
+	// wg waits for the loops started by Start.
+	wg sync.WaitGroup
+	// sendMu is held while sending, so sinks are closed only between sends.
+	sendMu    sync.Mutex
+	mu        sync.RWMutex
+	threshold int
+	keyword   string
+	easing    string
+	// smoothing is the weight of the previous calorie in an exponential moving average.
+	smoothing float64
+	paused    atomic.Bool
+	schedule  *schedule
+	idle      atomic.Bool
+	calorie   atomic.Value
+	source    source
+	store     *store
+	health    *health
+	history   *history
+	poll      pollConfig
+	weights   weightsConfig
+	retries   *retryQueues
+	// pollInterval is the current interval of the calculation loop.
+	pollInterval    atomic.Int64
+	sinks           []sink
+	sendInterval    time.Duration
 	intervalHistory []float64
+	// recentTweets are the newest tweets of the last poll.
+	recentTweets []*tweet
+	fade         *crossfade
+	override     *override
+	// idleSample is sent while the schedule is idle. Only the send loop uses it.
+	idleSample *sample
 }
 
+// crossfade blends the sent calorie from a fixed value into the calculated ones.
+type crossfade struct {
+	from     int32
+	start    time.Time
+	duration time.Duration
+}
+
+func (s scaleSettings) validate() error {
+	if s.Keyword == "" {
+		return fmt.Errorf("keyword must not be empty")
+	}
+	if s.Threshold <= 0 {
+		return fmt.Errorf("threshold must be positive: %d", s.Threshold)
+	}
+	if _, ok := easings[s.Easing]; !ok {
+		return fmt.Errorf("unknown easing: %s", s.Easing)
+	}
+	if s.Smoothing < 0 || 1 <= s.Smoothing {
+		return fmt.Errorf("smoothing must be in [0, 1): %f", s.Smoothing)
+	}
+	return nil
+}
+
+func (s *calorieScale) Settings() scaleSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return scaleSettings{
+		Keyword:   s.keyword,
+		Threshold: s.threshold,
+		Easing:    s.easing,
+		Smoothing: s.smoothing,
+	}
+}
+
+// SetSettings applies new settings from the next calculation on. The
+// interval history is replaced by the stored one of the new keyword when the
+// keyword changes, since it doesn't describe the new keyword.
+func (s *calorieScale) SetSettings(settings scaleSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keyword != settings.Keyword {
+		s.intervalHistory = s.store.Baseline(settings.Keyword, maxHistory)
+	}
+	s.keyword = settings.Keyword
+	s.threshold = settings.Threshold
+	s.easing = settings.Easing
+	s.smoothing = settings.Smoothing
+}
+
+// Pause stops polling and sending until Resume.
+func (s *calorieScale) Pause() {
+	s.paused.Store(true)
+}
+
+func (s *calorieScale) Resume() {
+	s.paused.Store(false)
+}
+
+func (s *calorieScale) Paused() bool {
+	return s.paused.Load()
+}
+
+// Active reports whether the schedule allows polling and sending at t.
+func (s *calorieScale) Active(t time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.schedule.active(t)
+}
+
+func (s *calorieScale) SetSchedule(schedule *schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule = schedule
+}
+
+func (s *calorieScale) Sinks() []sink {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sinks
+}
+
+func (s *calorieScale) SetSinks(sinks []sink) {
+	s.mu.Lock()
+	s.sinks = sinks
+	s.mu.Unlock()
+	s.retries.retain(sinks)
+}
+
+// RemoveSinks stops sending to sinks and closes them, after the send in
+// progress if any.
+func (s *calorieScale) RemoveSinks(sinks []sink) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	removed := make(map[sink]bool, len(sinks))
+	for _, sk := range sinks {
+		removed[sk] = true
+	}
+	s.mu.Lock()
+	kept := make([]sink, 0, len(s.sinks))
+	for _, sk := range s.sinks {
+		if !removed[sk] {
+			kept = append(kept, sk)
+		}
+	}
+	s.sinks = kept
+	s.mu.Unlock()
+	s.retries.retain(kept)
+	closeSinks(sinks)
+}
+
+// Start runs the send and calculation loops until Stop is called or the
+// context of the scale is done.
 func (s *calorieScale) Start() {
-	log.Printf("Starting...\n")
+	slog.Info("Starting")
 
+	s.wg.Add(1)
 	go func() {
-		ticker := time.NewTicker(s.oscInterval)
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.sendInterval)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
 				s.sendCalorie()
 			case <-s.ctx.Done():
-				ticker.Stop()
-				break
+				return
 			}
 		}
 	}()
 
+	// Without a source the samples are published from outside, e.g. by a replay.
+	if s.source == nil {
+		return
+	}
+	s.wg.Add(1)
 	go func() {
-		ticker := time.NewTicker(6 * time.Second)
+		defer s.wg.Done()
+		timer := time.NewTimer(s.PollInterval())
+		defer timer.Stop()
 		for {
 			select {
-			case <-ticker.C:
+			case <-timer.C:
 				s.calculateCalorie()
+				timer.Reset(s.PollInterval())
 			case <-s.ctx.Done():
-				ticker.Stop()
-				break
+				return
 			}
 		}
 	}()
 }
 
-func (s *calorieScale) sendCalorie() {
+// Stop stops the loops and the retry queues, and waits for the running poll
+// and sends to finish. The sinks are left open for Park and the owner to close.
+func (s *calorieScale) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	s.retries.Stop()
+	slog.Info("Stopped")
+}
+
+// Park sends value to every sink once, e.g. to leave the outputs at rest
+// after Stop.
+func (s *calorieScale) Park(value int32) {
+	s.mu.RLock()
+	keyword := s.keyword
+	s.mu.RUnlock()
+	slog.Info("Parking", "calorie", value)
+	s.send(&sample{Keyword: keyword, Calorie: value, Time: time.Now()})
+}
+
+// Latest returns the last calculated sample, or nil before the first calculation.
+func (s *calorieScale) Latest() *sample {
 	calorie := s.calorie.Load()
 	if calorie == nil {
-		return
+		return nil
 	}
+	return calorie.(*sample)
+}
 
-	msg := osc.NewMessage("/calorie")
-	msg.Append(calorie.(int32))
-	if err := s.oscClient.Send(msg); err != nil {
-		log.Printf("An error occured on send osc message: %+v\n", err)
+// Crossfade blends the sent calorie from the one sent now into the calculated ones over d.
+func (s *calorieScale) Crossfade(d time.Duration) {
+	current := s.output()
+	if current == nil || d <= 0 {
 		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fade = &crossfade{from: current.Calorie, start: time.Now(), duration: d}
 }
 
-func (s *calorieScale) calculateCalorie() {
-	result, _, err := s.twitterClient.Search.Tweets(&twitter.SearchTweetParams{
-		Query:      s.keyword,
-		ResultType: "recent",
-		Count:      100,
-	})
-	if err != nil {
-		log.Printf("An error occured on gathering tweets: %+v\n", err)
+// output returns the sample to send, which is the latest one blended by a
+// running crossfade and replaced by a manual override.
+func (s *calorieScale) output() *sample {
+	latest := s.Latest()
+	if latest == nil {
+		return nil
+	}
+	s.mu.RLock()
+	fade := s.fade
+	override := s.override
+	s.mu.RUnlock()
+
+	now := time.Now()
+	calorie := latest.Calorie
+	if fade != nil {
+		if p := float64(now.Sub(fade.start)) / float64(fade.duration); p < 1 {
+			calorie = int32(math.Round(float64(fade.from) + float64(latest.Calorie-fade.from)*p))
+		}
+	}
+	calorie = override.apply(calorie, now)
+	if calorie == latest.Calorie {
+		return latest
+	}
+	blended := *latest
+	blended.Calorie = calorie
+	return &blended
+}
+
+func (s *calorieScale) sendCalorie() {
+	if s.Paused() {
 		return
 	}
 
-	var sum float64
-	for i := len(result.Statuses) - 2; 0 <= i; i-- {
-		next := result.Statuses[i]
-		prev := result.Statuses[i+1]
-		nextTime, err := next.CreatedAtTime()
-		if err != nil {
-			log.Printf("An error occured on next.CreatedAtTime() v=%s: %+v\n", next.CreatedAt, err)
-			return
+	now := time.Now()
+	active := s.Active(now)
+	if s.idle.Swap(!active) == active {
+		slog.Info("Schedule changed", "active", active)
+	}
+	calorie := s.output()
+	if active {
+		s.idleSample = nil
+	} else {
+		s.mu.RLock()
+		keyword, idle := s.keyword, s.schedule.idle
+		s.mu.RUnlock()
+		// Reused while idle, so the sinks deduping on the time send it once.
+		if s.idleSample == nil || s.idleSample.Keyword != keyword || s.idleSample.Calorie != idle {
+			s.idleSample = &sample{Keyword: keyword, Calorie: idle, Time: now}
 		}
+		calorie = s.idleSample
+	}
+	if calorie == nil {
+		return
+	}
+	s.send(calorie)
+}
 
-		prevTime, err := prev.CreatedAtTime()
+// send sends calorie to every sink.
+func (s *calorieScale) send(calorie *sample) {
+	// The send links to the calculation of the sample, which ran in another cycle.
+	ctx, span := tracer.Start(s.ctx, "send",
+		trace.WithLinks(trace.Link{SpanContext: calorie.spanContext}),
+		trace.WithAttributes(attribute.String("keyword", calorie.Keyword), attribute.Int("calorie", int(calorie.Calorie))))
+	defer span.End()
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	for _, sk := range s.Sinks() {
+		// A sink with a retry queue is sent to by the queue.
+		if s.retries.enqueue(sk, calorie, time.Now()) {
+			continue
+		}
+		_, sinkSpan := tracer.Start(ctx, "sink.send", trace.WithAttributes(attribute.String("sink", sk.Name())))
+		err := sk.Send(calorie)
+		s.observeSend(sk, calorie, err)
 		if err != nil {
-			log.Printf("An error occured on prev.CreatedAtTime() v=%s: %+v\n", prev.CreatedAt, err)
-			return
+			sinkSpan.RecordError(err)
+			sinkSpan.SetStatus(codes.Error, err.Error())
 		}
+		sinkSpan.End()
+	}
+}
 
-		diff := nextTime.Sub(prevTime).Seconds()
-		if diff < 0 {
-			diff = 0
-		}
+func (s *calorieScale) observeSend(sk sink, calorie *sample, err error) {
+	s.health.observeSend(sk.Name(), err)
+	if err != nil {
+		sinkSendFailuresCounter.WithLabelValues(sk.Name()).Inc()
+		slog.Error("An error occured on send", "sink", sk.Name(), "keyword", calorie.Keyword, "err", err)
+	}
+}
+
+func (s *calorieScale) calculateCalorie() {
+	s.health.observeTick()
+	if s.Paused() || !s.Active(time.Now()) {
+		return
+	}
 
-		sum += diff
+	settings := s.Settings()
+	ctx, span := tracer.Start(s.ctx, "calculate", trace.WithAttributes(attribute.String("keyword", settings.Keyword)))
+	defer span.End()
+	tweets, err := s.source.Recent(ctx, settings.Keyword)
+	p := &poll{Time: time.Now(), Source: s.source.Name(), Keyword: settings.Keyword, Tweets: len(tweets), Err: err}
+	if len(tweets) > 0 {
+		p.Newest = tweets[0].Time
+		p.Oldest = tweets[len(tweets)-1].Time
+	}
+	s.store.RecordPoll(p)
+	s.health.observePoll(err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		apiErrorsCounter.WithLabelValues(settings.Keyword).Inc()
+		slog.Error("An error occured on gathering tweets", "source", s.source.Name(), "keyword", settings.Keyword, "err", err)
+		return
 	}
+	tweetsFetchedCounter.WithLabelValues(settings.Keyword).Add(float64(len(tweets)))
+	s.adaptPollInterval(settings.Keyword, tweets)
 
-	avgInterval := sum / float64(len(result.Statuses)-1)
+	_, computeSpan := tracer.Start(ctx, "compute")
+	defer computeSpan.End()
+	avgInterval, counted, ok := averageInterval(tweets, &s.weights)
+	if !ok {
+		slog.Warn("Too few tweets to calculate", "keyword", settings.Keyword, "tweets", len(tweets), "counted", counted)
+		return
+	}
+	s.mu.Lock()
+	if s.keyword != settings.Keyword {
+		// The keyword changed while searching, so this result is stale.
+		s.mu.Unlock()
+		return
+	}
 	t := 1 - math.Min(1, avgInterval/(s.getHistoryAverage()*2))
-	calorie := int32(easeInOutCubic(t) * 100)
+	value := easings[settings.Easing](t) * 100
+	if latest := s.Latest(); latest != nil && latest.Keyword == settings.Keyword {
+		value = float64(latest.Calorie)*settings.Smoothing + value*(1-settings.Smoothing)
+	}
+	calorie := int32(math.Round(value))
 	s.addHistory(avgInterval)
+	s.recentTweets = tweets[:min(len(tweets), maxRecentTweets)]
+	s.mu.Unlock()
+	computeSpan.SetAttributes(attribute.Float64("avgInterval", avgInterval), attribute.Int("calorie", int(calorie)))
 
-	log.Printf("Calculated keyword=%s tweets=%d avgInterval=%f, calorie=%d\n",
-		s.keyword, len(result.Statuses), avgInterval, calorie)
-	s.calorie.Store(calorie)
+	slog.Info("Calculated", "keyword", settings.Keyword, "tweets", counted, "avgInterval", avgInterval, "calorie", calorie)
+	s.Publish(&sample{
+		Keyword:     settings.Keyword,
+		Tweets:      counted,
+		AvgInterval: avgInterval,
+		Calorie:     calorie,
+		Time:        time.Now(),
+		spanContext: span.SpanContext(),
+	})
+}
+
+// Publish makes sm the latest sample to send and records it in the database.
+func (s *calorieScale) Publish(sm *sample) {
+	s.PublishReplayed(sm)
+	s.store.RecordSample(sm)
+}
+
+// PublishReplayed makes sm the latest sample to send without recording it,
+// as a replayed sample is in the database as of when it was recorded.
+func (s *calorieScale) PublishReplayed(sm *sample) {
+	calorieGauge.WithLabelValues(sm.Keyword).Set(float64(sm.Calorie))
+	avgIntervalGauge.WithLabelValues(sm.Keyword).Set(sm.AvgInterval)
+	s.calorie.Store(sm)
+	s.history.Add(sm)
+}
+
+// History returns the published samples newer than t, oldest first. The ones
+// older than the kept history are read from the database when there is one.
+func (s *calorieScale) History(t time.Time) []*sample {
+	if s.store != nil && !t.IsZero() && t.Before(time.Now().Add(-s.history.duration)) {
+		samples, err := s.store.Samples("", t)
+		if err == nil {
+			return samples
+		}
+		slog.Error("An error occured on load history", "err", err)
+	}
+	return s.history.Since(t)
+}
+
+// restoreHistory fills the history with the stored samples of the last d,
+// so it outlives a restart.
+func (s *calorieScale) restoreHistory(d time.Duration) {
+	if s.store == nil {
+		return
+	}
+	samples, err := s.store.Samples("", time.Now().Add(-d))
+	if err != nil {
+		slog.Error("An error occured on restore history", "err", err)
+		return
+	}
+	for _, sm := range samples {
+		s.history.Add(sm)
+	}
+}
+
+// RecentTweets returns the newest tweets of the last poll, newest first.
+func (s *calorieScale) RecentTweets() []*tweet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.recentTweets
 }
 
 func (s *calorieScale) addHistory(v float64) {
@@ -170,39 +531,202 @@ func (s *calorieScale) getHistoryAverage() float64 {
 	return sum / float64(len(s.intervalHistory))
 }
 
-func easeInOutCubic(x float64) float64 {
-	if x < .5 {
-		return 4 * x * x * x
-	} else {
-		return (x-1)*(2*x-2)*(2*x-2) + 1
+func main() {
+	if len(os.Args) > 1 {
+		commands := map[string]func([]string) bool{
+			"doctor":       runDoctor,
+			"fake-twitter": runFakeTwitter,
+			"bench":        runBench,
+			"service":      runService,
+		}
+		if run, ok := commands[os.Args[1]]; ok {
+			if !run(os.Args[2:]) {
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	if isService() {
+		runAsService()
+		return
 	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	run(quit)
 }
 
-func main() {
-	var (
-		threshold           = flag.Int("threshold", 6, "int flag")
-		keyword             = flag.String("keyword", "#youtube", "string flag")
-		oscHost             = flag.String("oscHost", "localhost", "string flag")
-		oscPort             = flag.Int("oscPort", 8765, "int flag")
-		twitterClientID     = flag.String("twitterClientID", "-", "string flag")
-		twitterClientSecret = flag.String("twitterClientSecret", "-", "string flag")
-	)
-	flag.Parse()
-
-	log.Printf("Initializing... threshold=%d keyword=%s oscHost=%s oscPort=%d\n", *threshold, *keyword, *oscHost, *oscPort)
+// run runs the scale configured by the command line until quit receives.
+func run(quit <-chan os.Signal) {
+	c, err := parseConfig(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fatal("An error occured on load config", "err", err)
+	}
+	var logs *logLines
+	if c.Dashboard {
+		logs = newLogLines(dashboardLogs)
+		logOutput = logs
+	}
+	setupLogging(&c.Log)
+	if c.Tracing.Endpoint != "" {
+		shutdown, err := setupTracing(context.Background(), &c.Tracing, c.Log.Pipeline)
+		if err != nil {
+			fatal("An error occured on setup tracing", "err", err)
+		}
+		defer shutdown(context.Background())
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
+	mux := http.NewServeMux()
+	settings, sinksParam := c.presetSettings(c.Preset)
+	schedule, err := c.Schedule.schedule()
+	if err != nil {
+		fatal("An error occured on load schedule", "err", err)
+	}
+	var st *store
+	if c.Database.Path != "" {
+		if st, err = openStore(ctx, &c.Database); err != nil {
+			fatal("An error occured on open database", "err", err)
+		}
+		defer st.Close()
+	}
+	var src source
+	var recording []*sample
+	if c.Replay.Path != "" {
+		if recording, err = loadRecording(c.Replay.Path); err != nil {
+			fatal("An error occured on load recording", "err", err)
+		}
+	} else if src, err = newSource(ctx, c); err != nil {
+		fatal("An error occured on open source", "err", err)
+	}
+	entries, err := openSinks(ctx, sinksParam, nil, c.DryRun, closeSinks)
+	if err != nil {
+		closeSinks(entrySinks(entries))
+		fatal("An error occured on open sinks", "err", err)
+	}
+	fixed := make([]sink, 0)
+	if c.HTTP.WebSocket {
+		ws := newWebSocketSink()
+		mux.Handle("/ws", ws)
+		fixed = append(fixed, ws)
+	}
+	if c.HTTP.SSE {
+		events := newSSESink()
+		mux.Handle("/events", events)
+		fixed = append(fixed, events)
+	}
+	var grpcServer *grpcSink
+	if c.GRPC.Addr != "" {
+		grpcServer = newGRPCSink()
+		fixed = append(fixed, grpcServer)
+	}
+
+	if c.DryRun {
+		for i, sk := range fixed {
+			fixed[i] = newDryRunSink(sk.Name(), sk)
+		}
+	}
+
+	retries, err := newRetryQueues(&c.Retry)
+	if err != nil {
+		closeSinks(entrySinks(entries))
+		fatal("An error occured on open dead letter", "err", err)
+	}
+	defer retries.Close()
+
+	sinks := append(entrySinks(entries), fixed...)
+	slog.Info("Initializing", "preset", c.Preset, "threshold", settings.Threshold, "keyword", settings.Keyword,
+		"easing", settings.Easing, "sinks", len(sinks), "dryRun", c.DryRun)
 	s := newCalorieScale(ctx, &CalorieScaleParam{
-		Threshold:           *threshold,
-		Keyword:             *keyword,
-		OSCHost:             *oscHost,
-		OSCPort:             *oscPort,
-		TwitterClientID:     *twitterClientID,
-		TwitterClientSecret: *twitterClientSecret,
+		Threshold: settings.Threshold,
+		Keyword:   settings.Keyword,
+		Easing:    settings.Easing,
+		Smoothing: settings.Smoothing,
+		Sinks:     sinks,
+		Schedule:  schedule,
+		Source:    src,
+		Store:     st,
+		History:   c.History,
+		Poll:      c.Poll,
+		Weights:   c.Weights,
+		Retries:   retries,
 	})
-	go s.Start()
+	r := newReloader(ctx, os.Args[1:], s, c, entries, fixed)
+	defer r.Close()
+	if c.Path != "" && c.WatchConfig {
+		go r.Watch(c.Path, configWatchInterval)
+	}
+
+	if c.Snapshot.Path != "" {
+		restoreSnapshot(&c.Snapshot, s)
+		go saveSnapshots(ctx, c.Snapshot.Path, s)
+	}
+	s.Start()
+	if recording != nil {
+		go replay(ctx, recording, s, c.Replay.Speed, c.Replay.Loop)
+	}
+	// servers are waited for on shutdown so in-flight requests finish before the sinks close.
+	var servers sync.WaitGroup
+	if c.HTTP.Addr != "" {
+		registerAPI(mux, s)
+		registerHealth(mux, s, c.HTTP.StaleAfter)
+		if c.HTTP.AdminToken != "" {
+			registerAdminAPI(mux, s, r, c.HTTP.AdminToken)
+		}
+		mux.Handle("/metrics", promhttp.Handler())
+		if c.HTTP.UI {
+			registerUI(mux)
+		}
+		servers.Go(func() { serveHTTP(ctx, c.HTTP.Addr, mux) })
+	}
+	if c.HTTP.PprofAddr != "" {
+		servers.Go(func() { servePprof(ctx, c.HTTP.PprofAddr) })
+	}
+	if grpcServer != nil {
+		servers.Go(func() { grpcServer.Serve(ctx, c.GRPC.Addr, s) })
+	}
+	if c.Interactive {
+		go readOverrides(ctx, os.Stdin, s, c.OverrideDuration)
+	}
+	if c.OSCControl.Addr != "" {
+		go serveOSCControl(ctx, c.OSCControl.Addr, c.HTTP.AdminToken, r, s)
+	}
+	dashboardDone := make(chan struct{})
+	if c.Dashboard {
+		go func() {
+			newDashboard(os.Stdout, s, logs).Run(ctx)
+			close(dashboardDone)
+		}()
+	} else {
+		close(dashboardDone)
+	}
+
+	go sdWatchdog(ctx, s, c.HTTP.StaleAfter)
+	sdNotify("READY=1")
 
-	quit := make(chan os.Signal)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
-	cancel()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for {
+		select {
+		case <-hup:
+			r.Reload()
+		case sig := <-quit:
+			slog.Info("Shutting down", "signal", sig)
+			sdNotify("STOPPING=1")
+			s.Stop()
+			if c.Park {
+				s.Park(c.ParkValue)
+			}
+			cancel()
+			// Wait for the dashboard to leave the alternate screen before logging again.
+			<-dashboardDone
+			servers.Wait()
+			if c.Snapshot.Path != "" {
+				if err := saveSnapshot(c.Snapshot.Path, s.Snapshot()); err != nil {
+					slog.Error("An error occured on save snapshot", "err", err)
+				}
+			}
+			return
+		}
+	}
 }
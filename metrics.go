@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsNamespace = "twitter_calorie"
+)
+
+var (
+	calorieGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "calorie",
+		Help:      "Last calculated calorie.",
+	}, []string{"keyword"})
+	avgIntervalGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "avg_interval_seconds",
+		Help:      "Average interval between the fetched tweets.",
+	}, []string{"keyword"})
+	tweetsFetchedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "tweets_fetched_total",
+		Help:      "Number of tweets fetched from the search API.",
+	}, []string{"keyword"})
+	apiErrorsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_errors_total",
+		Help:      "Number of failed Twitter API requests.",
+	}, []string{"keyword"})
+	sinkSendFailuresCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sink_send_failures_total",
+		Help:      "Number of failed sends per sink.",
+	}, []string{"sink"})
+	sinkRetryQueuedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "sink_retry_queued",
+		Help:      "Number of samples waiting to be sent again per sink.",
+	}, []string{"sink"})
+	sinkDroppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sink_dropped_total",
+		Help:      "Number of samples a sink failed to send and gave up on, by full queue, expired or closed sink.",
+	}, []string{"sink", "reason"})
+	rateLimitRemainingGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limit_remaining",
+		Help:      "Remaining Twitter API requests in the current rate limit window by endpoint.",
+	}, []string{"endpoint"})
+	pollIntervalGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "poll_interval_seconds",
+		Help:      "Interval the source is polled at.",
+	})
+	rateLimitLimitGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limit_limit",
+		Help:      "Twitter API requests allowed per rate limit window by endpoint.",
+	}, []string{"endpoint"})
+	rateLimitResetGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limit_reset_timestamp_seconds",
+		Help:      "Unix time the current Twitter API rate limit window of the endpoint resets at.",
+	}, []string{"endpoint"})
+	rateLimitProjectedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "rate_limit_projected",
+		Help:      "Twitter API requests polling will make until the rate limit window of the endpoint resets.",
+	}, []string{"endpoint"})
+	oscBackupActiveGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "osc_backup_active",
+		Help:      "1 while an OSC sink sends to its backup because the primary stopped responding.",
+	}, []string{"primary"})
+)
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	midiNoteOff       = 0x80
+	midiNoteOn        = 0x90
+	midiControlChange = 0xB0
+	midiVelocity      = 127
+)
+
+// midiNoteTrigger fires Note when calorie rises above Threshold.
+type midiNoteTrigger struct {
+	Threshold int32
+	Note      byte
+}
+
+type MIDISinkParam struct {
+	Device  string `yaml:"device"`
+	Channel int    `yaml:"channel"`
+	CC      int    `yaml:"cc"`
+	// Notes are threshold:note pairs such as "80:60,95:62".
+	Notes string `yaml:"notes"`
+	// BurstNote fires when calorie jumps by BurstDelta at once. Negative disables it.
+	BurstNote  int `yaml:"burstNote"`
+	BurstDelta int `yaml:"burstDelta"`
+}
+
+func defaultMIDISinkParam() *MIDISinkParam {
+	return &MIDISinkParam{
+		Channel:    1,
+		CC:         1,
+		BurstNote:  -1,
+		BurstDelta: 30,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *MIDISinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain MIDISinkParam
+	*p = *defaultMIDISinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *MIDISinkParam) enabled() bool {
+	return p.Device != ""
+}
+
+// validateMIDISinks fails on enabled MIDI sinks where there are no raw ALSA
+// devices to write to.
+func validateMIDISinks(params []*MIDISinkParam) error {
+	if runtime.GOOS == "linux" {
+		return nil
+	}
+	for _, p := range params {
+		if p.enabled() {
+			return fmt.Errorf("midi sinks need raw ALSA MIDI devices and run on Linux only: %s", p.Device)
+		}
+	}
+	return nil
+}
+
+// midiSink writes calorie as a MIDI control change to a raw MIDI device,
+// e.g. /dev/snd/midiC1D0. Virtual ports created by snd-virmidi show up as
+// raw MIDI devices too.
+type midiSink struct {
+	device     *os.File
+	channel    byte
+	cc         byte
+	triggers   []midiNoteTrigger
+	burstNote  int
+	burstDelta int32
+	last       *sample
+}
+
+func newMIDISink(param *MIDISinkParam) (*midiSink, error) {
+	if param.Channel < 1 || 16 < param.Channel {
+		return nil, fmt.Errorf("midi channel must be in 1-16: %d", param.Channel)
+	}
+	if param.CC < 0 || 127 < param.CC {
+		return nil, fmt.Errorf("midi cc must be in 0-127: %d", param.CC)
+	}
+	if 127 < param.BurstNote {
+		return nil, fmt.Errorf("midi burst note must be in 0-127: %d", param.BurstNote)
+	}
+
+	triggers, err := parseMIDINoteTriggers(param.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(param.Device, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &midiSink{
+		device:     f,
+		channel:    byte(param.Channel - 1),
+		cc:         byte(param.CC),
+		triggers:   triggers,
+		burstNote:  param.BurstNote,
+		burstDelta: int32(param.BurstDelta),
+	}, nil
+}
+
+func (m *midiSink) Name() string {
+	return "midi"
+}
+
+func (m *midiSink) Send(s *sample) error {
+	msg := []byte{midiControlChange | m.channel, m.cc, calorieToMIDI(s.Calorie)}
+	if m.last != nil && !m.last.Time.Equal(s.Time) {
+		for _, t := range m.triggers {
+			if m.last.Calorie <= t.Threshold && t.Threshold < s.Calorie {
+				msg = append(msg, m.note(t.Note)...)
+			}
+		}
+		if 0 <= m.burstNote && m.burstDelta <= s.Calorie-m.last.Calorie {
+			msg = append(msg, m.note(byte(m.burstNote))...)
+		}
+	}
+	m.last = s
+
+	_, err := m.device.Write(msg)
+	return err
+}
+
+func (m *midiSink) Close() error {
+	return m.device.Close()
+}
+
+// note returns a note-on immediately followed by its note-off, which is
+// enough for samplers and cue stacks playing one-shots.
+func (m *midiSink) note(n byte) []byte {
+	return []byte{
+		midiNoteOn | m.channel, n, midiVelocity,
+		midiNoteOff | m.channel, n, 0,
+	}
+}
+
+func calorieToMIDI(calorie int32) byte {
+	if calorie < 0 {
+		return 0
+	}
+	if 100 < calorie {
+		return 127
+	}
+	return byte(calorie * 127 / 100)
+}
+
+// parseMIDINoteTriggers parses a list of threshold:note pairs such as "80:60,95:62".
+func parseMIDINoteTriggers(v string) ([]midiNoteTrigger, error) {
+	triggers := make([]midiNoteTrigger, 0)
+	if v == "" {
+		return triggers, nil
+	}
+
+	for _, pair := range strings.Split(v, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid midi note trigger: %s", pair)
+		}
+		threshold, err := strconv.Atoi(kv[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid midi note trigger threshold: %s", pair)
+		}
+		note, err := strconv.Atoi(kv[1])
+		if err != nil || note < 0 || 127 < note {
+			return nil, fmt.Errorf("invalid midi note trigger note: %s", pair)
+		}
+		triggers = append(triggers, midiNoteTrigger{
+			Threshold: int32(threshold),
+			Note:      byte(note),
+		})
+	}
+	return triggers, nil
+}
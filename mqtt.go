@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	mqttConnectTimeout = 10 * time.Second
+	mqttPublishTimeout = 5 * time.Second
+)
+
+type MQTTSinkParam struct {
+	Broker   string `yaml:"broker"`
+	ClientID string `yaml:"clientID"`
+	Topic    string `yaml:"topic"`
+	QoS      int    `yaml:"qos"`
+	Retain   bool   `yaml:"retain"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// CACert, ClientCert and ClientKey are PEM files used for ssl:// and tls:// brokers.
+	CACert        string             `yaml:"caCert"`
+	ClientCert    string             `yaml:"clientCert"`
+	ClientKey     string             `yaml:"clientKey"`
+	Insecure      bool               `yaml:"insecure"`
+	HomeAssistant HomeAssistantParam `yaml:"homeAssistant"`
+	// Encoding of the calorie.v1.Sample payload, json or protobuf.
+	Encoding string `yaml:"encoding"`
+}
+
+func defaultMQTTSinkParam() *MQTTSinkParam {
+	return &MQTTSinkParam{
+		ClientID: "twitter-calorie",
+		Topic:    "twitter-calorie/calorie",
+		Encoding: sampleEncodingJSON,
+		HomeAssistant: HomeAssistantParam{
+			Prefix: "homeassistant",
+			NodeID: "twitter_calorie",
+		},
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *MQTTSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain MQTTSinkParam
+	*p = *defaultMQTTSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *MQTTSinkParam) enabled() bool {
+	return p.Broker != ""
+}
+
+// mqttSink publishes every new sample to a topic.
+type mqttSink struct {
+	client   mqtt.Client
+	topic    string
+	qos      byte
+	retain   bool
+	encoding string
+	last     time.Time
+	// availability is the Home Assistant availability topic, empty without discovery.
+	availability string
+}
+
+func newMQTTSink(param *MQTTSinkParam) (*mqttSink, error) {
+	if param.QoS < 0 || 2 < param.QoS {
+		return nil, fmt.Errorf("mqtt qos must be in 0-2: %d", param.QoS)
+	}
+	if err := validateSampleEncoding(param.Encoding); err != nil {
+		return nil, err
+	}
+	if param.HomeAssistant.Discovery && param.Encoding != sampleEncodingJSON {
+		return nil, fmt.Errorf("home assistant discovery needs the json encoding: %s", param.Encoding)
+	}
+
+	tlsConfig, err := newMQTTTLSConfig(param)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(param.Broker).
+		SetClientID(param.ClientID).
+		SetUsername(param.Username).
+		SetPassword(param.Password).
+		SetTLSConfig(tlsConfig).
+		SetAutoReconnect(true).
+		SetConnectRetry(true)
+	if ha := &param.HomeAssistant; ha.Discovery {
+		config, err := haSensorConfigPayload(ha, param.Topic)
+		if err != nil {
+			return nil, err
+		}
+		availability := haAvailabilityTopic(param.Topic)
+		opts.SetWill(availability, haPayloadOffline, byte(param.QoS), true)
+		opts.SetOnConnectHandler(func(c mqtt.Client) {
+			// Announce again on every reconnect in case the broker lost retained messages.
+			c.Publish(haConfigTopic(ha), byte(param.QoS), true, config)
+			c.Publish(availability, byte(param.QoS), true, haPayloadOnline)
+		})
+	}
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); !token.WaitTimeout(mqttConnectTimeout) {
+		return nil, fmt.Errorf("timed out connecting to mqtt broker: %s", param.Broker)
+	} else if err := token.Error(); err != nil {
+		return nil, err
+	}
+
+	m := &mqttSink{
+		client:   client,
+		topic:    param.Topic,
+		qos:      byte(param.QoS),
+		retain:   param.Retain,
+		encoding: param.Encoding,
+	}
+	if param.HomeAssistant.Discovery {
+		m.availability = haAvailabilityTopic(param.Topic)
+	}
+	return m, nil
+}
+
+func newMQTTTLSConfig(param *MQTTSinkParam) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: param.Insecure,
+	}
+
+	if param.CACert != "" {
+		pem, err := os.ReadFile(param.CACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", param.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if param.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(param.ClientCert, param.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+func (m *mqttSink) Name() string {
+	return "mqtt"
+}
+
+func (m *mqttSink) Send(s *sample) error {
+	if m.last.Equal(s.Time) {
+		return nil
+	}
+	payload, err := encodeSample(s, m.encoding)
+	if err != nil {
+		return err
+	}
+	if err := m.publish(m.topic, payload); err != nil {
+		return err
+	}
+	// Marked sent only now, so a failed sample is sent again when retried.
+	m.last = s.Time
+	return nil
+}
+
+func (m *mqttSink) publish(topic string, payload []byte) error {
+	token := m.client.Publish(topic, m.qos, m.retain, payload)
+	if !token.WaitTimeout(mqttPublishTimeout) {
+		return fmt.Errorf("timed out publishing to mqtt topic: %s", topic)
+	}
+	return token.Error()
+}
+
+func (m *mqttSink) Close() error {
+	var err error
+	if m.availability != "" {
+		// The will is only sent on an unclean disconnect, so go offline explicitly.
+		token := m.client.Publish(m.availability, m.qos, true, haPayloadOffline)
+		if !token.WaitTimeout(mqttPublishTimeout) {
+			err = fmt.Errorf("timed out publishing to mqtt topic: %s", m.availability)
+		} else {
+			err = token.Error()
+		}
+	}
+	m.client.Disconnect(uint(time.Second / time.Millisecond))
+	return err
+}
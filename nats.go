@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	natsPublishTimeout = 5 * time.Second
+)
+
+type NATSSinkParam struct {
+	URL         string `yaml:"url"`
+	Subject     string `yaml:"subject"`
+	Credentials string `yaml:"credentials"`
+	JetStream   bool   `yaml:"jetStream"`
+	// Encoding of the calorie.v1.Sample data, json or protobuf.
+	Encoding string `yaml:"encoding"`
+}
+
+func defaultNATSSinkParam() *NATSSinkParam {
+	return &NATSSinkParam{
+		Subject:  "twitter-calorie.calorie",
+		Encoding: sampleEncodingJSON,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *NATSSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain NATSSinkParam
+	*p = *defaultNATSSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *NATSSinkParam) enabled() bool {
+	return p.URL != ""
+}
+
+// natsSink publishes every new sample to a subject with its content type in
+// a header, optionally through JetStream so publishes are acknowledged and
+// persisted.
+type natsSink struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	subject  string
+	encoding string
+	last     time.Time
+}
+
+func newNATSSink(param *NATSSinkParam) (*natsSink, error) {
+	if err := validateSampleEncoding(param.Encoding); err != nil {
+		return nil, err
+	}
+	opts := []nats.Option{
+		nats.Name("twitter-calorie"),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			slog.Warn("Disconnected from nats", "err", err)
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			slog.Info("Reconnected to nats", "url", nc.ConnectedUrl())
+		}),
+	}
+	if param.Credentials != "" {
+		opts = append(opts, nats.UserCredentials(param.Credentials))
+	}
+
+	conn, err := nats.Connect(param.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &natsSink{
+		conn:     conn,
+		subject:  param.Subject,
+		encoding: param.Encoding,
+	}
+	if param.JetStream {
+		if n.js, err = jetstream.New(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+func (n *natsSink) Name() string {
+	return "nats"
+}
+
+func (n *natsSink) Send(s *sample) error {
+	if n.last.Equal(s.Time) {
+		return nil
+	}
+	data, err := encodeSample(s, n.encoding)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(n.subject)
+	msg.Data = data
+	msg.Header.Set("Content-Type", sampleContentType(n.encoding))
+	if n.js == nil {
+		err = n.conn.PublishMsg(msg)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), natsPublishTimeout)
+		defer cancel()
+		_, err = n.js.PublishMsg(ctx, msg)
+	}
+	if err != nil {
+		return err
+	}
+	n.last = s.Time
+	return nil
+}
+
+// Close flushes the publishes still buffered for up to natsPublishTimeout
+// before closing the connection.
+func (n *natsSink) Close() error {
+	err := n.conn.FlushTimeout(natsPublishTimeout)
+	n.conn.Close()
+	return err
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+	"golang.org/x/net/ipv4"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// OSCSinkParam sends to a host, or to every receiver listening on a
+// broadcast address such as 192.168.0.255 or a multicast group such as
+// 239.0.0.1.
+type OSCSinkParam struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// Interface is the network interface to send multicast on, e.g. eth1.
+	// Empty uses the default route.
+	Interface string `yaml:"interface"`
+	// TTL is the number of hops multicast goes, 1 for the local network.
+	TTL int `yaml:"ttl"`
+	// BackupHost is sent to instead while Host stops answering the /ping
+	// sent every ProbeInterval for ProbeTimeout. BackupPort defaults to Port.
+	BackupHost    string        `yaml:"backupHost"`
+	BackupPort    int           `yaml:"backupPort"`
+	ProbeInterval time.Duration `yaml:"probeInterval"`
+	ProbeTimeout  time.Duration `yaml:"probeTimeout"`
+}
+
+func defaultOSCSinkParam() *OSCSinkParam {
+	return &OSCSinkParam{
+		Host:          "localhost",
+		Port:          8765,
+		TTL:           1,
+		ProbeInterval: time.Second,
+		ProbeTimeout:  3 * time.Second,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *OSCSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain OSCSinkParam
+	*p = *defaultOSCSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *OSCSinkParam) enabled() bool {
+	return p.Host != ""
+}
+
+type oscSink struct {
+	client *osc.Client
+	// conn and group are set instead of client for a multicast host.
+	conn  *ipv4.PacketConn
+	group net.Addr
+	// failover is set instead of client for a host with a backup.
+	failover *oscFailover
+}
+
+func newOSCSink(param *OSCSinkParam) (*oscSink, error) {
+	ip := net.ParseIP(param.Host)
+	if param.BackupHost != "" {
+		if ip != nil && ip.IsMulticast() {
+			return nil, fmt.Errorf("a multicast host can't have a backup: %s", param.Host)
+		}
+		if param.ProbeInterval <= 0 || param.ProbeTimeout <= param.ProbeInterval {
+			return nil, fmt.Errorf("OSC probe timeout must be longer than its interval: %s, %s", param.ProbeTimeout, param.ProbeInterval)
+		}
+		f, err := newOSCFailover(param)
+		if err != nil {
+			return nil, err
+		}
+		return &oscSink{failover: f}, nil
+	}
+	if ip == nil || !ip.IsMulticast() {
+		// Broadcast needs nothing more, as Go enables it on every UDP socket.
+		return &oscSink{client: osc.NewClient(param.Host, param.Port)}, nil
+	}
+	if ip.To4() == nil {
+		return nil, fmt.Errorf("multicast is supported on IPv4 only: %s", param.Host)
+	}
+
+	c, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	conn := ipv4.NewPacketConn(c)
+	if err := setupOSCMulticast(conn, param); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	group, err := net.ResolveUDPAddr("udp4", net.JoinHostPort(param.Host, strconv.Itoa(param.Port)))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &oscSink{conn: conn, group: group}, nil
+}
+
+func setupOSCMulticast(conn *ipv4.PacketConn, param *OSCSinkParam) error {
+	if err := conn.SetMulticastTTL(param.TTL); err != nil {
+		return err
+	}
+	// Receivers on this host hear the group as well.
+	if err := conn.SetMulticastLoopback(true); err != nil {
+		return err
+	}
+	if param.Interface == "" {
+		return nil
+	}
+	ifi, err := net.InterfaceByName(param.Interface)
+	if err != nil {
+		return err
+	}
+	return conn.SetMulticastInterface(ifi)
+}
+
+func (o *oscSink) Name() string {
+	return "osc"
+}
+
+func (o *oscSink) Send(s *sample) error {
+	msg := osc.NewMessage("/calorie")
+	msg.Append(s.Calorie)
+	if o.client != nil {
+		return o.client.Send(msg)
+	}
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if o.failover != nil {
+		return o.failover.Send(data)
+	}
+	_, err = o.conn.WriteTo(data, nil, o.group)
+	return err
+}
+
+func (o *oscSink) Close() error {
+	switch {
+	case o.failover != nil:
+		return o.failover.Close()
+	case o.conn != nil:
+		return o.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// oscHistoryMaxSamples caps the samples of a /history reply, the newest ones.
+const oscHistoryMaxSamples = 600
+
+// serveOSCControl listens on addr for control messages until ctx is done:
+//
+//	/preset <name> [crossfade seconds]
+//	/history [seconds]
+//
+// With token set every message takes it as its first argument, as in
+// /preset <token> <name>. /history is served only then, and replies to the
+// sender with a /history/sample <unix seconds> <calorie> message per sample
+// of the history, oldest first, then /history/end.
+func serveOSCControl(ctx context.Context, addr, token string, r *reloader, s *calorieScale) {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		slog.Error("An error occured on listen osc control", "err", err)
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	slog.Info("Listening osc control", "addr", addr)
+	c := &oscControl{conn: conn, token: token, reloader: r, scale: s}
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("An error occured on osc control server", "err", err)
+			}
+			return
+		}
+		packet, err := osc.ParsePacket(string(buf[:n]))
+		if err != nil {
+			slog.Warn("An error occured on parse osc control", "from", from, "err", err)
+			continue
+		}
+		c.dispatch(packet, from)
+	}
+}
+
+type oscControl struct {
+	conn     net.PacketConn
+	token    string
+	reloader *reloader
+	scale    *calorieScale
+}
+
+func (c *oscControl) dispatch(packet osc.Packet, from net.Addr) {
+	switch p := packet.(type) {
+	case *osc.Message:
+		c.handle(p, from)
+	case *osc.Bundle:
+		for _, msg := range p.Messages {
+			c.handle(msg, from)
+		}
+		for _, b := range p.Bundles {
+			c.dispatch(b, from)
+		}
+	}
+}
+
+func (c *oscControl) handle(msg *osc.Message, from net.Addr) {
+	args := msg.Arguments
+	if c.token != "" {
+		given, _ := firstString(args)
+		if subtle.ConstantTimeCompare([]byte(given), []byte(c.token)) != 1 {
+			slog.Warn("An error occured on osc control, unauthorized", "address", msg.Address, "from", from)
+			return
+		}
+		args = args[1:]
+	}
+
+	switch msg.Address {
+	case "/preset":
+		c.preset(msg.Address, args)
+	case "/history":
+		if c.token == "" {
+			slog.Warn("An error occured on osc control, /history needs the admin token set", "from", from)
+			return
+		}
+		c.history(msg.Address, args, from)
+	}
+}
+
+func (c *oscControl) preset(address string, args []interface{}) {
+	name, ok := firstString(args)
+	if !ok {
+		slog.Warn("An error occured on osc control, preset name must be a string", "address", address)
+		return
+	}
+
+	fade := c.reloader.Crossfade()
+	if len(args) > 1 {
+		seconds, ok := oscFloat(args[1])
+		if !ok {
+			slog.Warn("An error occured on osc control, crossfade must be a number", "address", address)
+			return
+		}
+		fade = time.Duration(seconds * float64(time.Second))
+	}
+	if err := c.reloader.SwitchPreset(name, fade); err != nil {
+		slog.Warn("An error occured on osc control", "address", address, "err", err)
+	}
+}
+
+func (c *oscControl) history(address string, args []interface{}, from net.Addr) {
+	var since time.Time
+	if len(args) > 0 {
+		seconds, ok := oscFloat(args[0])
+		if !ok {
+			slog.Warn("An error occured on osc control, seconds must be a number", "address", address)
+			return
+		}
+		since = time.Now().Add(-time.Duration(seconds * float64(time.Second)))
+	}
+
+	samples := c.scale.History(since)
+	if len(samples) > oscHistoryMaxSamples {
+		samples = samples[len(samples)-oscHistoryMaxSamples:]
+	}
+	for _, sm := range samples {
+		if err := c.reply(osc.NewMessage("/history/sample", float64(sm.Time.UnixMilli())/1000, sm.Calorie), from); err != nil {
+			slog.Warn("An error occured on osc control reply", "address", address, "err", err)
+			return
+		}
+	}
+	if err := c.reply(osc.NewMessage("/history/end"), from); err != nil {
+		slog.Warn("An error occured on osc control reply", "address", address, "err", err)
+	}
+}
+
+func (c *oscControl) reply(msg *osc.Message, to net.Addr) error {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.WriteTo(data, to)
+	return err
+}
+
+func firstString(args []interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	s, ok := args[0].(string)
+	return s, ok
+}
+
+// oscFloat reads a numeric OSC argument.
+func oscFloat(arg interface{}) (float64, bool) {
+	switch v := arg.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hypebeast/go-osc/osc"
+)
+
+// oscFailover sends to the primary destination while it answers the /ping
+// probes, and to the backup while it doesn't. Any packet a destination sends
+// back to the probing port counts as an answer, e.g. a /pong.
+type oscFailover struct {
+	conn    *net.UDPConn
+	primary *net.UDPAddr
+	backup  *net.UDPAddr
+	timeout time.Duration
+	done    chan struct{}
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	answered map[string]time.Time
+	onBackup bool
+}
+
+func newOSCFailover(param *OSCSinkParam) (*oscFailover, error) {
+	primary, err := net.ResolveUDPAddr("udp", net.JoinHostPort(param.Host, strconv.Itoa(param.Port)))
+	if err != nil {
+		return nil, err
+	}
+	port := param.BackupPort
+	if port == 0 {
+		port = param.Port
+	}
+	backup, err := net.ResolveUDPAddr("udp", net.JoinHostPort(param.BackupHost, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &oscFailover{
+		conn:    conn,
+		primary: primary,
+		backup:  backup,
+		timeout: param.ProbeTimeout,
+		done:    make(chan struct{}),
+		// The primary has until the timeout to answer first.
+		answered: map[string]time.Time{primary.String(): time.Now()},
+	}
+	oscBackupActiveGauge.WithLabelValues(primary.String()).Set(0)
+	f.wg.Add(2)
+	go f.receive()
+	go f.probe(param.ProbeInterval)
+	return f, nil
+}
+
+func (f *oscFailover) receive() {
+	defer f.wg.Done()
+	buf := make([]byte, 65535)
+	for {
+		_, addr, err := f.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-f.done:
+				return
+			default:
+			}
+			// Refused probes surface here on some systems, and mean no answer.
+			continue
+		}
+		f.mu.Lock()
+		f.answered[addr.String()] = time.Now()
+		f.mu.Unlock()
+	}
+}
+
+func (f *oscFailover) probe(interval time.Duration) {
+	defer f.wg.Done()
+	ping, err := osc.NewMessage("/ping").MarshalBinary()
+	if err != nil {
+		slog.Error("An error occured on marshal OSC ping", "err", err)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-f.done:
+			return
+		}
+		f.conn.WriteToUDP(ping, f.primary)
+		f.conn.WriteToUDP(ping, f.backup)
+		f.check(time.Now())
+	}
+}
+
+// check switches to the backup once the primary has not answered for the
+// timeout, and back as soon as it answers again.
+func (f *oscFailover) check(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	alive := func(addr *net.UDPAddr) bool {
+		return now.Sub(f.answered[addr.String()]) < f.timeout
+	}
+	switch {
+	case !f.onBackup && !alive(f.primary):
+		f.onBackup = true
+		slog.Warn("OSC primary stopped responding, failing over", "primary", f.primary, "backup", f.backup, "backupResponding", alive(f.backup))
+	case f.onBackup && alive(f.primary):
+		f.onBackup = false
+		slog.Info("OSC primary recovered, switching back", "primary", f.primary)
+	default:
+		return
+	}
+	active := 0.0
+	if f.onBackup {
+		active = 1
+	}
+	oscBackupActiveGauge.WithLabelValues(f.primary.String()).Set(active)
+}
+
+func (f *oscFailover) Send(data []byte) error {
+	f.mu.Lock()
+	addr := f.primary
+	if f.onBackup {
+		addr = f.backup
+	}
+	f.mu.Unlock()
+	_, err := f.conn.WriteToUDP(data, addr)
+	return err
+}
+
+func (f *oscFailover) Close() error {
+	close(f.done)
+	err := f.conn.Close()
+	f.wg.Wait()
+	return err
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minCalorie = 0
+	maxCalorie = 100
+)
+
+// override replaces or offsets the sent calorie until it expires.
+type override struct {
+	value int32
+	// offset adds value to the calculated calorie instead of replacing it.
+	offset bool
+	until  time.Time
+}
+
+// Override replaces the sent calorie with value, or adds it with offset, for d.
+func (s *calorieScale) Override(value int32, offset bool, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.override = &override{value: value, offset: offset, until: time.Now().Add(d)}
+}
+
+func (s *calorieScale) ClearOverride() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.override = nil
+}
+
+// apply returns the calorie to send in place of calorie at t.
+func (o *override) apply(calorie int32, t time.Time) int32 {
+	if o == nil || !t.Before(o.until) {
+		return calorie
+	}
+	if !o.offset {
+		return o.value
+	}
+	return min(max(calorie+o.value, minCalorie), maxCalorie)
+}
+
+// readOverrides reads override commands from r line by line until it ends
+// or ctx is done. Each command lasts for d:
+//
+//	80      send 80
+//	+20     add 20 to the calculated calorie, -20 subtracts
+//	w       "wild", send the maximum
+//	s       suppress, send the minimum
+//	c       clear the override, as does an empty line
+func readOverrides(ctx context.Context, r io.Reader, s *calorieScale, d time.Duration) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() && ctx.Err() == nil {
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "", "c":
+			s.ClearOverride()
+			slog.Info("Override cleared")
+			continue
+		case "w":
+			line = strconv.Itoa(maxCalorie)
+		case "s":
+			line = strconv.Itoa(minCalorie)
+		}
+
+		value, offset, err := parseOverride(line)
+		if err != nil {
+			slog.Warn("An error occured on parse override", "err", err)
+			continue
+		}
+		s.Override(value, offset, d)
+		slog.Info("Override", "value", value, "offset", offset, "duration", d)
+	}
+	if err := scanner.Err(); err != nil {
+		slog.Error("An error occured on read overrides", "err", err)
+	}
+}
+
+func parseOverride(v string) (int32, bool, error) {
+	offset := strings.HasPrefix(v, "+") || strings.HasPrefix(v, "-")
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid override: %s", v)
+	}
+	if !offset && (n < minCalorie || maxCalorie < n) {
+		return 0, false, fmt.Errorf("override must be in [%d, %d]: %d", minCalorie, maxCalorie, n)
+	}
+	return int32(n), offset, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// pollConfig bounds the interval the source is polled at. Between the bounds
+// the interval follows the tweet rate, and it is fixed when they are equal.
+type pollConfig struct {
+	MinInterval time.Duration `yaml:"minInterval"`
+	MaxInterval time.Duration `yaml:"maxInterval"`
+}
+
+func (c *pollConfig) validate() error {
+	if c.MinInterval <= 0 {
+		return fmt.Errorf("poll min interval must be positive: %s", c.MinInterval)
+	}
+	if c.MaxInterval < c.MinInterval {
+		return fmt.Errorf("poll max interval must not be below the min interval: %s", c.MaxInterval)
+	}
+	return nil
+}
+
+// nextPollInterval is half the time the fetched tweets span, so consecutive
+// polls overlap and a busy keyword is polled often while a quiet one backs
+// off, clamped to c. It is then stretched to what the rate limit rl allows
+// until its reset for polls of requests requests, which may exceed the max
+// interval.
+func nextPollInterval(c *pollConfig, tweets []*tweet, rl *rateLimitStatus, requests int, now time.Time) time.Duration {
+	interval := c.MaxInterval
+	if len(tweets) > 1 {
+		interval = tweets[0].Time.Sub(tweets[len(tweets)-1].Time) / 2
+	}
+	interval = min(max(interval, c.MinInterval), c.MaxInterval)
+
+	if rl != nil && rl.Reset.After(now) {
+		untilReset := rl.Reset.Sub(now)
+		if rl.Remaining <= 0 {
+			return max(interval, untilReset)
+		}
+		interval = max(interval, untilReset*time.Duration(requests)/time.Duration(rl.Remaining))
+	}
+	return interval
+}
+
+// PollInterval returns the interval the source is polled at now.
+func (s *calorieScale) PollInterval() time.Duration {
+	return time.Duration(s.pollInterval.Load())
+}
+
+func (s *calorieScale) adaptPollInterval(keyword string, tweets []*tweet) {
+	requests := 1
+	if m, ok := s.source.(multiRequestSource); ok {
+		requests = m.pollRequests(keyword)
+	}
+	interval := nextPollInterval(&s.poll, tweets, currentRateLimit(keyword), requests, time.Now())
+	if prev := s.pollInterval.Swap(int64(interval)); prev != int64(interval) {
+		slog.Debug("Poll interval changed", "interval", interval)
+	}
+	// Requests are projected one by one.
+	setProjectedPollInterval(interval / time.Duration(requests))
+	pollIntervalGauge.Set(interval.Seconds())
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// presetConfig is a named set of settings and sinks to switch to at once,
+// e.g. one per act. Fields left out are taken from the top level config.
+type presetConfig struct {
+	Keyword string `yaml:"keyword"`
+	// Query replaces the keyword with a built query.
+	Query     *queryConfig `yaml:"query"`
+	Threshold int          `yaml:"threshold"`
+	Easing    string       `yaml:"easing"`
+	Smoothing *float64     `yaml:"smoothing"`
+	// Sinks replace the top level sinks while the preset is active.
+	Sinks *SinksParam `yaml:"sinks"`
+}
+
+// presetSettings returns the settings and sinks of the named preset. The
+// empty name, or a name without a preset, gives the top level ones.
+func (c *config) presetSettings(name string) (scaleSettings, *SinksParam) {
+	settings := c.scaleSettings()
+	sinks := &c.Sinks
+	p := c.Presets[name]
+	if p == nil {
+		return settings, sinks
+	}
+
+	if p.Keyword != "" {
+		settings.Keyword = p.Keyword
+	}
+	if p.Query != nil && !p.Query.empty() {
+		// validatePresets reports the error.
+		settings.Keyword, _ = p.Query.query()
+	}
+	if p.Threshold != 0 {
+		settings.Threshold = p.Threshold
+	}
+	if p.Easing != "" {
+		settings.Easing = p.Easing
+	}
+	if p.Smoothing != nil {
+		settings.Smoothing = *p.Smoothing
+	}
+	if p.Sinks != nil {
+		sinks = p.Sinks
+	}
+	return settings, sinks
+}
+
+func (c *config) validatePresets() error {
+	if _, ok := c.Presets[c.Preset]; c.Preset != "" && !ok {
+		return fmt.Errorf("unknown preset: %s", c.Preset)
+	}
+	for name := range c.Presets {
+		if name == "" {
+			return fmt.Errorf("preset name must not be empty")
+		}
+		if p := c.Presets[name]; p.Query != nil && !p.Query.empty() {
+			if _, err := p.Query.query(); err != nil {
+				return fmt.Errorf("preset %s: %w", name, err)
+			}
+		}
+		settings, sinks := c.presetSettings(name)
+		if err := settings.validate(); err != nil {
+			return fmt.Errorf("preset %s: %w", name, err)
+		}
+		if err := sinks.validate(); err != nil {
+			return fmt.Errorf("preset %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SwitchPreset applies the named preset, crossfading the sent calorie over
+// fade. The empty name switches back to the top level settings.
+func (r *reloader) SwitchPreset(name string, fade time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.config.Presets[name]; name != "" && !ok {
+		return fmt.Errorf("unknown preset: %s", name)
+	}
+	r.scale.Crossfade(fade)
+	r.preset = name
+	r.apply()
+	return nil
+}
+
+// Preset returns the name of the active preset, or empty for the top level settings.
+func (r *reloader) Preset() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.preset
+}
+
+// Presets returns the names of the configured presets in order.
+func (r *reloader) Presets() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.config.Presets))
+	for name := range r.config.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Crossfade returns the default crossfade of preset switches.
+func (r *reloader) Crossfade() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.config.Crossfade
+}
@@ -0,0 +1,364 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: calorie/v1/calorie.proto
+
+package caloriev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Sample is a calculated calorie. It is the message of every sink sending
+// structured data: gRPC as is, Kafka, NATS and MQTT as JSON or protobuf, and
+// the webhook, SSE, WebSocket, Redis sinks and REST API in its JSON mapping.
+type Sample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keyword       string                 `protobuf:"bytes,1,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	Tweets        int32                  `protobuf:"varint,2,opt,name=tweets,proto3" json:"tweets,omitempty"`
+	AvgInterval   float64                `protobuf:"fixed64,3,opt,name=avg_interval,json=avgInterval,proto3" json:"avg_interval,omitempty"`
+	Calorie       int32                  `protobuf:"varint,4,opt,name=calorie,proto3" json:"calorie,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sample) Reset() {
+	*x = Sample{}
+	mi := &file_calorie_v1_calorie_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sample) ProtoMessage() {}
+
+func (x *Sample) ProtoReflect() protoreflect.Message {
+	mi := &file_calorie_v1_calorie_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sample.ProtoReflect.Descriptor instead.
+func (*Sample) Descriptor() ([]byte, []int) {
+	return file_calorie_v1_calorie_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Sample) GetKeyword() string {
+	if x != nil {
+		return x.Keyword
+	}
+	return ""
+}
+
+func (x *Sample) GetTweets() int32 {
+	if x != nil {
+		return x.Tweets
+	}
+	return 0
+}
+
+func (x *Sample) GetAvgInterval() float64 {
+	if x != nil {
+		return x.AvgInterval
+	}
+	return 0
+}
+
+func (x *Sample) GetCalorie() int32 {
+	if x != nil {
+		return x.Calorie
+	}
+	return 0
+}
+
+func (x *Sample) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type GetLatestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestRequest) Reset() {
+	*x = GetLatestRequest{}
+	mi := &file_calorie_v1_calorie_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestRequest) ProtoMessage() {}
+
+func (x *GetLatestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calorie_v1_calorie_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestRequest) Descriptor() ([]byte, []int) {
+	return file_calorie_v1_calorie_proto_rawDescGZIP(), []int{1}
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_calorie_v1_calorie_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calorie_v1_calorie_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_calorie_v1_calorie_proto_rawDescGZIP(), []int{2}
+}
+
+type Status struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keyword       string                 `protobuf:"bytes,1,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	Threshold     int32                  `protobuf:"varint,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Sinks         []string               `protobuf:"bytes,3,rep,name=sinks,proto3" json:"sinks,omitempty"`
+	Latest        *Sample                `protobuf:"bytes,4,opt,name=latest,proto3" json:"latest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_calorie_v1_calorie_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_calorie_v1_calorie_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_calorie_v1_calorie_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Status) GetKeyword() string {
+	if x != nil {
+		return x.Keyword
+	}
+	return ""
+}
+
+func (x *Status) GetThreshold() int32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *Status) GetSinks() []string {
+	if x != nil {
+		return x.Sinks
+	}
+	return nil
+}
+
+func (x *Status) GetLatest() *Sample {
+	if x != nil {
+		return x.Latest
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_calorie_v1_calorie_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_calorie_v1_calorie_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_calorie_v1_calorie_proto_rawDescGZIP(), []int{4}
+}
+
+var File_calorie_v1_calorie_proto protoreflect.FileDescriptor
+
+const file_calorie_v1_calorie_proto_rawDesc = "" +
+	"\n" +
+	"\x18calorie/v1/calorie.proto\x12\n" +
+	"calorie.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x01\n" +
+	"\x06Sample\x12\x18\n" +
+	"\akeyword\x18\x01 \x01(\tR\akeyword\x12\x16\n" +
+	"\x06tweets\x18\x02 \x01(\x05R\x06tweets\x12!\n" +
+	"\favg_interval\x18\x03 \x01(\x01R\vavgInterval\x12\x18\n" +
+	"\acalorie\x18\x04 \x01(\x05R\acalorie\x12.\n" +
+	"\x04time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\x12\n" +
+	"\x10GetLatestRequest\"\x12\n" +
+	"\x10GetStatusRequest\"\x82\x01\n" +
+	"\x06Status\x12\x18\n" +
+	"\akeyword\x18\x01 \x01(\tR\akeyword\x12\x1c\n" +
+	"\tthreshold\x18\x02 \x01(\x05R\tthreshold\x12\x14\n" +
+	"\x05sinks\x18\x03 \x03(\tR\x05sinks\x12*\n" +
+	"\x06latest\x18\x04 \x01(\v2\x12.calorie.v1.SampleR\x06latest\"\x12\n" +
+	"\x10SubscribeRequest2\xcf\x01\n" +
+	"\x0eCalorieService\x12=\n" +
+	"\tGetLatest\x12\x1c.calorie.v1.GetLatestRequest\x1a\x12.calorie.v1.Sample\x12=\n" +
+	"\tGetStatus\x12\x1c.calorie.v1.GetStatusRequest\x1a\x12.calorie.v1.Status\x12?\n" +
+	"\tSubscribe\x12\x1c.calorie.v1.SubscribeRequest\x1a\x12.calorie.v1.Sample0\x01B?Z=github.com/miyukki/twitter-calorie/proto/calorie/v1;caloriev1b\x06proto3"
+
+var (
+	file_calorie_v1_calorie_proto_rawDescOnce sync.Once
+	file_calorie_v1_calorie_proto_rawDescData []byte
+)
+
+func file_calorie_v1_calorie_proto_rawDescGZIP() []byte {
+	file_calorie_v1_calorie_proto_rawDescOnce.Do(func() {
+		file_calorie_v1_calorie_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_calorie_v1_calorie_proto_rawDesc), len(file_calorie_v1_calorie_proto_rawDesc)))
+	})
+	return file_calorie_v1_calorie_proto_rawDescData
+}
+
+var file_calorie_v1_calorie_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_calorie_v1_calorie_proto_goTypes = []any{
+	(*Sample)(nil),                // 0: calorie.v1.Sample
+	(*GetLatestRequest)(nil),      // 1: calorie.v1.GetLatestRequest
+	(*GetStatusRequest)(nil),      // 2: calorie.v1.GetStatusRequest
+	(*Status)(nil),                // 3: calorie.v1.Status
+	(*SubscribeRequest)(nil),      // 4: calorie.v1.SubscribeRequest
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_calorie_v1_calorie_proto_depIdxs = []int32{
+	5, // 0: calorie.v1.Sample.time:type_name -> google.protobuf.Timestamp
+	0, // 1: calorie.v1.Status.latest:type_name -> calorie.v1.Sample
+	1, // 2: calorie.v1.CalorieService.GetLatest:input_type -> calorie.v1.GetLatestRequest
+	2, // 3: calorie.v1.CalorieService.GetStatus:input_type -> calorie.v1.GetStatusRequest
+	4, // 4: calorie.v1.CalorieService.Subscribe:input_type -> calorie.v1.SubscribeRequest
+	0, // 5: calorie.v1.CalorieService.GetLatest:output_type -> calorie.v1.Sample
+	3, // 6: calorie.v1.CalorieService.GetStatus:output_type -> calorie.v1.Status
+	0, // 7: calorie.v1.CalorieService.Subscribe:output_type -> calorie.v1.Sample
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_calorie_v1_calorie_proto_init() }
+func file_calorie_v1_calorie_proto_init() {
+	if File_calorie_v1_calorie_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_calorie_v1_calorie_proto_rawDesc), len(file_calorie_v1_calorie_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_calorie_v1_calorie_proto_goTypes,
+		DependencyIndexes: file_calorie_v1_calorie_proto_depIdxs,
+		MessageInfos:      file_calorie_v1_calorie_proto_msgTypes,
+	}.Build()
+	File_calorie_v1_calorie_proto = out.File
+	file_calorie_v1_calorie_proto_goTypes = nil
+	file_calorie_v1_calorie_proto_depIdxs = nil
+}
@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: calorie/v1/calorie.proto
+
+package caloriev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CalorieService_GetLatest_FullMethodName = "/calorie.v1.CalorieService/GetLatest"
+	CalorieService_GetStatus_FullMethodName = "/calorie.v1.CalorieService/GetStatus"
+	CalorieService_Subscribe_FullMethodName = "/calorie.v1.CalorieService/Subscribe"
+)
+
+// CalorieServiceClient is the client API for CalorieService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CalorieService streams calculated calorie samples of a running scale.
+type CalorieServiceClient interface {
+	// GetLatest returns the last calculated sample.
+	GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*Sample, error)
+	// GetStatus returns the configuration and state of the scale.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// Subscribe streams every new sample, starting with the latest one.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Sample], error)
+}
+
+type calorieServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCalorieServiceClient(cc grpc.ClientConnInterface) CalorieServiceClient {
+	return &calorieServiceClient{cc}
+}
+
+func (c *calorieServiceClient) GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*Sample, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Sample)
+	err := c.cc.Invoke(ctx, CalorieService_GetLatest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calorieServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, CalorieService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calorieServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Sample], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CalorieService_ServiceDesc.Streams[0], CalorieService_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Sample]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalorieService_SubscribeClient = grpc.ServerStreamingClient[Sample]
+
+// CalorieServiceServer is the server API for CalorieService service.
+// All implementations must embed UnimplementedCalorieServiceServer
+// for forward compatibility.
+//
+// CalorieService streams calculated calorie samples of a running scale.
+type CalorieServiceServer interface {
+	// GetLatest returns the last calculated sample.
+	GetLatest(context.Context, *GetLatestRequest) (*Sample, error)
+	// GetStatus returns the configuration and state of the scale.
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// Subscribe streams every new sample, starting with the latest one.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Sample]) error
+	mustEmbedUnimplementedCalorieServiceServer()
+}
+
+// UnimplementedCalorieServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCalorieServiceServer struct{}
+
+func (UnimplementedCalorieServiceServer) GetLatest(context.Context, *GetLatestRequest) (*Sample, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLatest not implemented")
+}
+func (UnimplementedCalorieServiceServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedCalorieServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Sample]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedCalorieServiceServer) mustEmbedUnimplementedCalorieServiceServer() {}
+func (UnimplementedCalorieServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeCalorieServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CalorieServiceServer will
+// result in compilation errors.
+type UnsafeCalorieServiceServer interface {
+	mustEmbedUnimplementedCalorieServiceServer()
+}
+
+func RegisterCalorieServiceServer(s grpc.ServiceRegistrar, srv CalorieServiceServer) {
+	// If the following call panics, it indicates UnimplementedCalorieServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CalorieService_ServiceDesc, srv)
+}
+
+func _CalorieService_GetLatest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalorieServiceServer).GetLatest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalorieService_GetLatest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalorieServiceServer).GetLatest(ctx, req.(*GetLatestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalorieService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalorieServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalorieService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalorieServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalorieService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CalorieServiceServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Sample]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalorieService_SubscribeServer = grpc.ServerStreamingServer[Sample]
+
+// CalorieService_ServiceDesc is the grpc.ServiceDesc for CalorieService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CalorieService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "calorie.v1.CalorieService",
+	HandlerType: (*CalorieServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLatest",
+			Handler:    _CalorieService_GetLatest_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _CalorieService_GetStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _CalorieService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "calorie/v1/calorie.proto",
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxQueryLength is the longest query the standard search API takes.
+const maxQueryLength = 500
+
+var (
+	hashtagPattern = regexp.MustCompile(`^[\p{L}\p{N}_]+$`)
+	mentionPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+	// plainTermPattern matches the terms that need no quoting.
+	plainTermPattern = regexp.MustCompile(`^[\p{L}\p{N}_#@]+$`)
+)
+
+// queryConfig composes the search query from terms, instead of writing the
+// keyword by hand. Hashtags and mentions are matched like Any terms, so
+//
+//	all: [live, tokyo], any: [encore], hashtags: [show], mentions: [band], exclude: [spam]
+//
+// searches `live tokyo (encore OR #show OR @band) -spam`.
+type queryConfig struct {
+	// All are the terms that must all appear. Terms with spaces match as phrases.
+	All []string `yaml:"all"`
+	// Any are the terms of which at least one must appear.
+	Any      []string `yaml:"any"`
+	Hashtags []string `yaml:"hashtags"`
+	Mentions []string `yaml:"mentions"`
+	// Exclude are the terms that must not appear.
+	Exclude []string `yaml:"exclude"`
+}
+
+func (c *queryConfig) empty() bool {
+	return len(c.All) == 0 && len(c.Any) == 0 && len(c.Hashtags) == 0 && len(c.Mentions) == 0 && len(c.Exclude) == 0
+}
+
+// query builds the search query, failing on terms that can't be searched.
+func (c *queryConfig) query() (string, error) {
+	parts := make([]string, 0)
+	for _, term := range c.All {
+		t, err := quoteTerm(term)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, t)
+	}
+
+	anyOf := make([]string, 0)
+	for _, term := range c.Any {
+		t, err := quoteTerm(term)
+		if err != nil {
+			return "", err
+		}
+		anyOf = append(anyOf, t)
+	}
+	for _, tag := range c.Hashtags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if !hashtagPattern.MatchString(tag) {
+			return "", fmt.Errorf("invalid hashtag: %s", tag)
+		}
+		anyOf = append(anyOf, "#"+tag)
+	}
+	for _, name := range c.Mentions {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+		if !mentionPattern.MatchString(name) {
+			return "", fmt.Errorf("invalid mention: %s", name)
+		}
+		anyOf = append(anyOf, "@"+name)
+	}
+	switch len(anyOf) {
+	case 0:
+	case 1:
+		parts = append(parts, anyOf[0])
+	default:
+		parts = append(parts, "("+strings.Join(anyOf, " OR ")+")")
+	}
+
+	if len(parts) == 0 {
+		return "", fmt.Errorf("query needs a term to match besides the excluded ones")
+	}
+	for _, term := range c.Exclude {
+		t, err := quoteTerm(term)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "-"+t)
+	}
+
+	q := strings.Join(parts, " ")
+	if n := utf8.RuneCountInString(q); n > maxQueryLength {
+		return "", fmt.Errorf("query is %d characters, longer than %d: %s", n, maxQueryLength, q)
+	}
+	return q, nil
+}
+
+// quoteTerm makes term match as it is written, quoting it as a phrase unless
+// it is a single plain word. A lone OR would be taken as the operator, so it
+// is quoted as well.
+func quoteTerm(term string) (string, error) {
+	term = strings.Join(strings.Fields(term), " ")
+	if term == "" {
+		return "", fmt.Errorf("query term must not be empty")
+	}
+	// Quotes can't be escaped in the search syntax.
+	if strings.Contains(term, `"`) {
+		return "", fmt.Errorf("query term must not contain quotes: %s", term)
+	}
+	if plainTermPattern.MatchString(term) && term != "OR" && !strings.HasPrefix(term, "-") {
+		return term, nil
+	}
+	return `"` + term + `"`, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateLimitStatus is the rate limit of a Twitter API endpoint as of a response.
+type rateLimitStatus struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	// Projected is the number of requests polling makes until the reset.
+	Projected int `json:"projected"`
+}
+
+// parseRateLimit reads the rate limit headers of a Twitter API response,
+// projecting the requests until the reset when polling every interval.
+func parseRateLimit(resp *http.Response, now time.Time, interval time.Duration) (*rateLimitStatus, error) {
+	remaining, err := strconv.Atoi(resp.Header.Get("x-rate-limit-remaining"))
+	if err != nil {
+		return nil, fmt.Errorf("no rate limit headers in response")
+	}
+	status := &rateLimitStatus{Remaining: remaining}
+	status.Limit, _ = strconv.Atoi(resp.Header.Get("x-rate-limit-limit"))
+	if reset, err := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64); err == nil {
+		status.Reset = time.Unix(reset, 0)
+	}
+	status.Projected = max(int(status.Reset.Sub(now)/interval), 0)
+	return status, nil
+}
+
+// exhausts reports whether polling runs out of requests before the window resets.
+func (r *rateLimitStatus) exhausts() bool {
+	return r.Remaining < r.Projected
+}
+
+// Twitter limits each endpoint in a bucket of its own.
+const (
+	rateLimitSearch   = "search"
+	rateLimitLists    = "lists"
+	rateLimitTimeline = "timeline"
+)
+
+var (
+	// projectedPollInterval is the interval the requests are projected with.
+	projectedPollInterval atomic.Int64
+
+	rateLimitMu sync.Mutex
+	// rateLimits are by endpoint, missing until a response had the headers.
+	rateLimits = make(map[string]*rateLimitStatus)
+	// rateLimitWarned is the reset of the window the exhaustion was warned of
+	// by endpoint, to warn once per window.
+	rateLimitWarned = make(map[string]time.Time)
+)
+
+func init() {
+	projectedPollInterval.Store(int64(calculateInterval))
+}
+
+// setProjectedPollInterval updates the interval the scale polls at for the projections.
+func setProjectedPollInterval(d time.Duration) {
+	projectedPollInterval.Store(int64(d))
+}
+
+// currentRateLimit returns the last observed rate limit of the endpoint
+// polling keyword, or nil.
+func currentRateLimit(keyword string) *rateLimitStatus {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	return rateLimits[rateLimitEndpoint(keyword)]
+}
+
+// observeRateLimit records the rate limit headers of a Twitter API response
+// of endpoint, and warns once a window when polling is projected to exhaust it.
+func observeRateLimit(endpoint string, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	status, err := parseRateLimit(resp, time.Now(), time.Duration(projectedPollInterval.Load()))
+	if err != nil {
+		return
+	}
+	rateLimitRemainingGauge.WithLabelValues(endpoint).Set(float64(status.Remaining))
+	rateLimitLimitGauge.WithLabelValues(endpoint).Set(float64(status.Limit))
+	rateLimitResetGauge.WithLabelValues(endpoint).Set(float64(status.Reset.Unix()))
+	rateLimitProjectedGauge.WithLabelValues(endpoint).Set(float64(status.Projected))
+	slog.Debug("Rate limit", "endpoint", endpoint, "remaining", status.Remaining, "limit", status.Limit, "reset", status.Reset, "projected", status.Projected)
+
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	rateLimits[endpoint] = status
+	if status.exhausts() && !rateLimitWarned[endpoint].Equal(status.Reset) {
+		rateLimitWarned[endpoint] = status.Reset
+		slog.Warn("Rate limit will be exhausted before the reset", "endpoint", endpoint, "remaining", status.Remaining,
+			"limit", status.Limit, "reset", status.Reset, "projected", status.Projected)
+	}
+}
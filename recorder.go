@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	recordFormatCSV   = "csv"
+	recordFormatJSONL = "jsonl"
+	recordTimeLayout  = "20060102-150405"
+)
+
+var recordCSVHeader = []string{"time", "keyword", "tweets", "avgInterval", "calorie"}
+
+type RecorderSinkParam struct {
+	// Path is the base file name. Every file gets the time it was opened
+	// appended, e.g. show.csv is written as show-20201010-183000.csv, and a
+	// counter when a file of that second exists, as in show-20201010-183000-1.csv.
+	Path   string `yaml:"path"`
+	Format string `yaml:"format"`
+	// RotateSize and RotateInterval start a new file once exceeded. Zero disables each.
+	RotateSize     int64         `yaml:"rotateSize"`
+	RotateInterval time.Duration `yaml:"rotateInterval"`
+}
+
+func defaultRecorderSinkParam() *RecorderSinkParam {
+	return &RecorderSinkParam{
+		Format: recordFormatCSV,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *RecorderSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain RecorderSinkParam
+	*p = *defaultRecorderSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *RecorderSinkParam) enabled() bool {
+	return p.Path != ""
+}
+
+// recorderSink appends every new sample to CSV or JSON Lines files for
+// post-event analysis and replay.
+type recorderSink struct {
+	param  *RecorderSinkParam
+	file   *os.File
+	size   int64
+	opened time.Time
+	last   time.Time
+}
+
+func newRecorderSink(param *RecorderSinkParam) (*recorderSink, error) {
+	if param.Format != recordFormatCSV && param.Format != recordFormatJSONL {
+		return nil, fmt.Errorf("unknown record format: %s", param.Format)
+	}
+
+	r := &recorderSink{
+		param: param,
+	}
+	if err := r.rotate(time.Now()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *recorderSink) Name() string {
+	return "recorder"
+}
+
+func (r *recorderSink) Send(s *sample) error {
+	if r.last.Equal(s.Time) {
+		return nil
+	}
+	now := time.Now()
+	if (0 < r.param.RotateSize && r.param.RotateSize <= r.size) ||
+		(0 < r.param.RotateInterval && r.param.RotateInterval <= now.Sub(r.opened)) {
+		if err := r.rotate(now); err != nil {
+			// Keep recording to the current file rather than losing the sample.
+			slog.Warn("An error occured on rotate recording", "err", err)
+		}
+	}
+
+	line, err := r.encode(s)
+	if err != nil {
+		return err
+	}
+	n, err := r.file.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		return err
+	}
+	r.last = s.Time
+	return nil
+}
+
+func (r *recorderSink) Close() error {
+	return r.file.Close()
+}
+
+// rotate opens a new file, keeping the current one until it did, so a
+// failure leaves the recorder writing where it was.
+func (r *recorderSink) rotate(now time.Time) error {
+	f, err := createRecording(r.param.Path, now)
+	if err != nil {
+		return err
+	}
+	var size int64
+	if r.param.Format == recordFormatCSV {
+		header, err := encodeCSV(recordCSVHeader)
+		if err == nil {
+			var n int
+			n, err = f.Write(header)
+			size = int64(n)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			slog.Warn("An error occured on close recording", "err", err)
+		}
+	}
+	r.file = f
+	r.size = size
+	r.opened = now
+	return nil
+}
+
+// createRecording creates a file named after path and now that doesn't exist yet.
+func createRecording(path string, now time.Time) (*os.File, error) {
+	ext := filepath.Ext(path)
+	base := fmt.Sprintf("%s-%s", strings.TrimSuffix(path, ext), now.Format(recordTimeLayout))
+	name := base + ext
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+func (r *recorderSink) encode(s *sample) ([]byte, error) {
+	if r.param.Format == recordFormatJSONL {
+		line, err := json.Marshal(s)
+		return append(line, '\n'), err
+	}
+	return encodeCSV([]string{
+		s.Time.Format(time.RFC3339Nano),
+		s.Keyword,
+		strconv.Itoa(s.Tweets),
+		strconv.FormatFloat(s.AvgInterval, 'f', -1, 64),
+		strconv.Itoa(int(s.Calorie)),
+	})
+}
+
+func encodeCSV(record []string) ([]byte, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(record)
+	w.Flush()
+	return []byte(b.String()), w.Error()
+}
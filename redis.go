@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	redisTimeout = 5 * time.Second
+)
+
+type RedisSinkParam struct {
+	URL     string `yaml:"url"`
+	Channel string `yaml:"channel"`
+	// Key holds the latest sample for consumers reading on demand. Empty disables it.
+	Key string        `yaml:"key"`
+	TTL time.Duration `yaml:"ttl"`
+}
+
+func defaultRedisSinkParam() *RedisSinkParam {
+	return &RedisSinkParam{
+		Channel: "twitter-calorie",
+		Key:     "twitter-calorie:latest",
+		TTL:     time.Minute,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *RedisSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain RedisSinkParam
+	*p = *defaultRedisSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *RedisSinkParam) enabled() bool {
+	return p.URL != ""
+}
+
+// redisSink publishes every new sample to a channel and keeps the latest one in a key.
+type redisSink struct {
+	client  *redis.Client
+	channel string
+	key     string
+	ttl     time.Duration
+	last    time.Time
+}
+
+func newRedisSink(param *RedisSinkParam) (*redisSink, error) {
+	opts, err := redis.ParseURL(param.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisSink{
+		client:  redis.NewClient(opts),
+		channel: param.Channel,
+		key:     param.Key,
+		ttl:     param.TTL,
+	}, nil
+}
+
+func (r *redisSink) Name() string {
+	return "redis"
+}
+
+func (r *redisSink) Send(s *sample) error {
+	if r.last.Equal(s.Time) {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	_, err = r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		if r.channel != "" {
+			p.Publish(ctx, r.channel, data)
+		}
+		if r.key != "" {
+			p.Set(ctx, r.key, data, r.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.last = s.Time
+	return nil
+}
+
+func (r *redisSink) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloader reapplies the config to a running scale. Settings and sinks are
+// swapped in place, and only the sinks whose config changed are reopened, so
+// outputs keep running through a reload.
+type reloader struct {
+	ctx     context.Context
+	args    []string
+	scale   *calorieScale
+	mu      sync.Mutex
+	entries []*sinkEntry
+	config  *config
+	// preset is the active preset, which is kept across reloads while it exists.
+	preset string
+	// dryRun is taken from the config at startup only.
+	dryRun bool
+	// fixed are the sinks served by the HTTP and gRPC servers, which live as long as the process.
+	fixed []sink
+}
+
+func newReloader(ctx context.Context, args []string, scale *calorieScale, c *config, entries []*sinkEntry, fixed []sink) *reloader {
+	return &reloader{
+		ctx:     ctx,
+		args:    args,
+		scale:   scale,
+		entries: entries,
+		config:  c,
+		preset:  c.Preset,
+		dryRun:  c.DryRun,
+		fixed:   fixed,
+	}
+}
+
+// Reload parses the command line and config file again and applies them.
+func (r *reloader) Reload() {
+	c, err := parseConfig(flag.NewFlagSet(os.Args[0], flag.ContinueOnError), r.args)
+	if err != nil {
+		slog.Error("An error occured on reload config, keeping the current one", "err", err)
+		return
+	}
+	r.Apply(c)
+}
+
+func (r *reloader) Apply(c *config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := c.Presets[r.preset]; !ok || c.Preset != r.config.Preset {
+		r.preset = c.Preset
+	}
+	r.config = c
+	setupLogging(&c.Log)
+	if schedule, err := c.Schedule.schedule(); err == nil {
+		r.scale.SetSchedule(schedule)
+	}
+	r.apply()
+}
+
+// apply applies the active preset of the config. r.mu must be held.
+func (r *reloader) apply() {
+	settings, sinksParam := r.config.presetSettings(r.preset)
+	entries, err := openSinks(r.ctx, sinksParam, r.entries, r.dryRun, r.scale.RemoveSinks)
+	if err != nil {
+		slog.Error("An error occured on reopen sinks", "err", err)
+	}
+	r.entries = entries
+	r.scale.SetSinks(append(entrySinks(entries), r.fixed...))
+	r.scale.SetSettings(settings)
+
+	slog.Info("Applied", "preset", r.preset, "threshold", settings.Threshold, "keyword", settings.Keyword,
+		"easing", settings.Easing, "sinks", len(entries)+len(r.fixed))
+}
+
+// Watch reloads whenever the modification time of path changes, until ctx is done.
+func (r *reloader) Watch(path string, interval time.Duration) {
+	var modTime time.Time
+	if fi, err := os.Stat(path); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			fi, err := os.Stat(path)
+			if err != nil || fi.ModTime().Equal(modTime) {
+				continue
+			}
+			modTime = fi.ModTime()
+			r.Reload()
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *reloader) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	closeSinks(entrySinks(r.entries))
+	closeSinks(r.fixed)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	remoteWriteTimeout    = 10 * time.Second
+	remoteWriteMaxBatch   = 1000
+	remoteWriteMaxBackoff = 30 * time.Second
+)
+
+type RemoteWriteSinkParam struct {
+	URL      string        `yaml:"url"`
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password"`
+	Interval time.Duration `yaml:"interval"`
+	Retries  int           `yaml:"retries"`
+	// Instance is attached as the instance label to every series when set.
+	Instance string `yaml:"instance"`
+}
+
+func defaultRemoteWriteSinkParam() *RemoteWriteSinkParam {
+	return &RemoteWriteSinkParam{
+		Interval: 15 * time.Second,
+		Retries:  5,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *RemoteWriteSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain RemoteWriteSinkParam
+	*p = *defaultRemoteWriteSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *RemoteWriteSinkParam) enabled() bool {
+	return p.URL != ""
+}
+
+// remoteWriteSink pushes samples with the Prometheus remote write protocol,
+// for venues where /metrics can't be scraped from outside.
+type remoteWriteSink struct {
+	param  *RemoteWriteSinkParam
+	labels map[string]string
+	client *http.Client
+	mu     sync.Mutex
+	batch  []*sample
+	last   time.Time
+	done   chan struct{}
+	// cancel aborts a flush of the push loop on Close.
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newRemoteWriteSink(ctx context.Context, param *RemoteWriteSinkParam) *remoteWriteSink {
+	labels := map[string]string{"job": "twitter-calorie"}
+	if param.Instance != "" {
+		labels["instance"] = param.Instance
+	}
+
+	r := &remoteWriteSink{
+		param:  param,
+		labels: labels,
+		client: &http.Client{Timeout: remoteWriteTimeout},
+		batch:  make([]*sample, 0),
+		done:   make(chan struct{}),
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(param.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.flush(ctx)
+			case <-r.done:
+				return
+			}
+		}
+	}()
+
+	return r
+}
+
+func (r *remoteWriteSink) Name() string {
+	return "remotewrite"
+}
+
+func (r *remoteWriteSink) Send(s *sample) error {
+	if r.last.Equal(s.Time) {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batch = append(r.batch, s)
+	if len(r.batch) > remoteWriteMaxBatch {
+		r.batch = r.batch[1:]
+	}
+	r.last = s.Time
+	return nil
+}
+
+// Close stops the push loop and pushes whatever is still batched, retrying
+// for up to remoteWriteTimeout so an endpoint that is down doesn't hold up
+// the shutdown or reload.
+func (r *remoteWriteSink) Close() error {
+	close(r.done)
+	r.cancel()
+	r.wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), remoteWriteTimeout)
+	defer cancel()
+	r.flush(ctx)
+	return nil
+}
+
+func (r *remoteWriteSink) flush(ctx context.Context) {
+	r.mu.Lock()
+	batch := r.batch
+	r.batch = make([]*sample, 0)
+	r.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	body := snappy.Encode(nil, r.encode(batch))
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		retry, err := r.push(ctx, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= r.param.Retries {
+			sinkSendFailuresCounter.WithLabelValues(r.Name()).Inc()
+			slog.Error("An error occured on remote write", "dropped", len(batch), "err", err)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			// Put the batch back for the flush of Close.
+			r.mu.Lock()
+			r.batch = append(batch, r.batch...)
+			if len(r.batch) > remoteWriteMaxBatch {
+				r.batch = r.batch[len(r.batch)-remoteWriteMaxBatch:]
+			}
+			r.mu.Unlock()
+			return
+		}
+		if backoff *= 2; backoff > remoteWriteMaxBackoff {
+			backoff = remoteWriteMaxBackoff
+		}
+	}
+}
+
+// push sends a single request, reporting whether a failure is worth retrying.
+func (r *remoteWriteSink) push(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.param.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if r.param.Username != "" {
+		req.SetBasicAuth(r.param.Username, r.param.Password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return false, nil
+	case resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("remote write returned %s: %s", resp.Status, msg)
+	default:
+		return false, fmt.Errorf("remote write returned %s: %s", resp.Status, msg)
+	}
+}
+
+// encode marshals the batch as a prometheus.WriteRequest protobuf message.
+func (r *remoteWriteSink) encode(batch []*sample) []byte {
+	series := map[string]func(*sample) float64{
+		metricsNamespace + "_calorie":              func(s *sample) float64 { return float64(s.Calorie) },
+		metricsNamespace + "_tweets":               func(s *sample) float64 { return float64(s.Tweets) },
+		metricsNamespace + "_avg_interval_seconds": func(s *sample) float64 { return s.AvgInterval },
+	}
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Samples of a series must share the exact label set, so group by keyword.
+	keywords := make([]string, 0)
+	byKeyword := make(map[string][]*sample)
+	for _, s := range batch {
+		if _, ok := byKeyword[s.Keyword]; !ok {
+			keywords = append(keywords, s.Keyword)
+		}
+		byKeyword[s.Keyword] = append(byKeyword[s.Keyword], s)
+	}
+
+	var req []byte
+	for _, keyword := range keywords {
+		for _, name := range names {
+			labels := map[string]string{"__name__": name, "keyword": keyword}
+			for k, v := range r.labels {
+				labels[k] = v
+			}
+
+			var ts []byte
+			for _, k := range sortedKeys(labels) {
+				var label []byte
+				label = protowire.AppendTag(label, 1, protowire.BytesType)
+				label = protowire.AppendString(label, k)
+				label = protowire.AppendTag(label, 2, protowire.BytesType)
+				label = protowire.AppendString(label, labels[k])
+				ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+				ts = protowire.AppendBytes(ts, label)
+			}
+			for _, s := range byKeyword[keyword] {
+				var point []byte
+				point = protowire.AppendTag(point, 1, protowire.Fixed64Type)
+				point = protowire.AppendFixed64(point, math.Float64bits(series[name](s)))
+				point = protowire.AppendTag(point, 2, protowire.VarintType)
+				point = protowire.AppendVarint(point, uint64(s.Time.UnixNano()/int64(time.Millisecond)))
+				ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+				ts = protowire.AppendBytes(ts, point)
+			}
+
+			req = protowire.AppendTag(req, 1, protowire.BytesType)
+			req = protowire.AppendBytes(req, ts)
+		}
+	}
+	return req
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
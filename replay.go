@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// replayLoopGap is the wait between the last sample and the first one of the next loop.
+	replayLoopGap = time.Second
+)
+
+// replayConfig re-emits the samples recorded by the recorder sink instead of
+// searching Twitter.
+type replayConfig struct {
+	// Path is a recording or a glob matching the rotated files of one, e.g.
+	// show-*.csv. Files ending in .jsonl are read as JSON Lines, others as CSV.
+	Path string `yaml:"path"`
+	// Speed scales the recorded time, e.g. 2 replays twice as fast.
+	Speed float64 `yaml:"speed"`
+	Loop  bool    `yaml:"loop"`
+}
+
+// loadRecording reads the samples of every file matching pattern in the order of their names.
+func loadRecording(pattern string) ([]*sample, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no recording matches %s", pattern)
+	}
+	sort.Strings(paths)
+
+	samples := make([]*sample, 0)
+	for _, path := range paths {
+		loaded, err := loadRecordingFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		samples = append(samples, loaded...)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples in %s", pattern)
+	}
+	return samples, nil
+}
+
+func loadRecordingFile(path string) ([]*sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if filepath.Ext(path) == "."+recordFormatJSONL {
+		return decodeJSONLRecording(f)
+	}
+	return decodeCSVRecording(f)
+}
+
+func decodeJSONLRecording(r io.Reader) ([]*sample, error) {
+	samples := make([]*sample, 0)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		s := &sample{}
+		if err := json.Unmarshal(scanner.Bytes(), s); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
+
+func decodeCSVRecording(r io.Reader) ([]*sample, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(recordCSVHeader)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]*sample, 0, len(records))
+	for n, record := range records {
+		if record[0] == recordCSVHeader[0] {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		tweets, err := strconv.Atoi(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		avgInterval, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		calorie, err := strconv.ParseInt(record[4], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		samples = append(samples, &sample{
+			Keyword:     record[1],
+			Tweets:      tweets,
+			AvgInterval: avgInterval,
+			Calorie:     int32(calorie),
+			Time:        t,
+		})
+	}
+	return samples, nil
+}
+
+// replay publishes the samples to s keeping their recorded intervals scaled
+// by speed, until the end or ctx is done. Replayed samples are stamped with
+// the time they are published, and not recorded in the database again.
+func replay(ctx context.Context, samples []*sample, s *calorieScale, speed float64, loop bool) {
+	slog.Info("Replaying", "samples", len(samples), "speed", speed, "loop", loop)
+	for looped := false; ; looped = true {
+		for i, recorded := range samples {
+			if i > 0 || looped {
+				wait := replayLoopGap
+				if i > 0 {
+					wait = time.Duration(float64(recorded.Time.Sub(samples[i-1].Time)) / speed)
+				}
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+			replayed := *recorded
+			replayed.Time = time.Now()
+			s.PublishReplayed(&replayed)
+		}
+		if !loop {
+			slog.Info("Replay finished")
+			return
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	dropReasonFull    = "full"
+	dropReasonExpired = "expired"
+	dropReasonClosed  = "closed"
+
+	retryMinBackoff = time.Second
+	retryMaxBackoff = 30 * time.Second
+)
+
+// retryConfig buffers the samples a sink failed to send, to send them in
+// order once it recovers instead of dropping them.
+type retryConfig struct {
+	// Queue is the number of samples buffered per sink. 0 disables retries.
+	Queue int `yaml:"queue"`
+	// MaxAge drops the buffered samples older than it. 0 keeps them until the queue is full.
+	MaxAge time.Duration `yaml:"maxAge"`
+	// DeadLetter is a file the dropped samples are appended to as JSON lines.
+	// Empty only counts them.
+	DeadLetter string `yaml:"deadLetter"`
+	// DeadLetterSize moves the file to DeadLetter.1 once exceeded, replacing
+	// the previous one, so at most twice the size is kept.
+	DeadLetterSize int64 `yaml:"deadLetterSize"`
+}
+
+func (c *retryConfig) validate() error {
+	if c.Queue < 0 {
+		return fmt.Errorf("retry queue must not be negative: %d", c.Queue)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("retry max age must not be negative: %s", c.MaxAge)
+	}
+	if c.DeadLetterSize <= 0 {
+		return fmt.Errorf("dead letter size must be positive: %d", c.DeadLetterSize)
+	}
+	return nil
+}
+
+// retryQueue is the samples waiting for a sink, oldest first, sent by a
+// goroutine of its own so a sink that is down doesn't hold up the others.
+type retryQueue struct {
+	sink    sink
+	samples []*sample
+	// lastErr is of the last failed send, for the dead letters.
+	lastErr error
+	// full is whether the queue overflowed since it last drained, to warn once.
+	full bool
+	wake chan struct{}
+	done chan struct{}
+	// stopped is closed once the goroutine returns.
+	stopped chan struct{}
+}
+
+// retryQueues holds a retry queue per sink.
+type retryQueues struct {
+	config     retryConfig
+	deadLetter *deadLetterLog
+	// observe is called with the result of every send.
+	observe func(sk sink, s *sample, err error)
+
+	mu      sync.Mutex
+	queues  map[sink]*retryQueue
+	stopped bool
+}
+
+func newRetryQueues(c *retryConfig) (*retryQueues, error) {
+	r := &retryQueues{
+		config:  *c,
+		observe: func(sink, *sample, error) {},
+		queues:  make(map[sink]*retryQueue),
+	}
+	if c.Queue > 0 && c.DeadLetter != "" {
+		var err error
+		if r.deadLetter, err = openDeadLetterLog(c.DeadLetter, c.DeadLetterSize); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// enqueue queues s to be sent to sk by its queue, and reports whether it
+// did. It doesn't when retries are disabled or stopped, and s is to be sent
+// directly.
+func (r *retryQueues) enqueue(sk sink, s *sample, now time.Time) bool {
+	if r == nil || r.config.Queue == 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return false
+	}
+	q := r.queues[sk]
+	if q == nil {
+		q = &retryQueue{
+			sink:    sk,
+			wake:    make(chan struct{}, 1),
+			done:    make(chan struct{}),
+			stopped: make(chan struct{}),
+		}
+		r.queues[sk] = q
+		go r.drain(q)
+	}
+	// The send loop sends the latest sample again until the next one.
+	if n := len(q.samples); n == 0 || !q.samples[n-1].Time.Equal(s.Time) {
+		q.samples = append(q.samples, s)
+	}
+	r.expire(q, now)
+	if over := len(q.samples) - r.config.Queue; over > 0 {
+		if !q.full {
+			q.full = true
+			slog.Warn("Retry queue is full, dropping the oldest samples", "sink", sk.Name(), "queue", r.config.Queue)
+		}
+		r.drop(sk, dropReasonFull, q.lastErr, q.samples[:over])
+		q.samples = q.samples[over:]
+	}
+	r.observeQueued(sk)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// drain sends the samples of q in order until q is stopped, backing off
+// while the sink fails.
+func (r *retryQueues) drain(q *retryQueue) {
+	defer close(q.stopped)
+	backoff := time.Duration(0)
+	for {
+		select {
+		case <-q.wake:
+		case <-q.done:
+			return
+		}
+		for {
+			r.mu.Lock()
+			r.expire(q, time.Now())
+			if len(q.samples) == 0 {
+				q.full = false
+				r.observeQueued(q.sink)
+				r.mu.Unlock()
+				break
+			}
+			s := q.samples[0]
+			r.mu.Unlock()
+
+			err := q.sink.Send(s)
+			r.observe(q.sink, s, err)
+			r.mu.Lock()
+			if err == nil {
+				// The sample may have been dropped meanwhile for a full queue.
+				if len(q.samples) > 0 && q.samples[0] == s {
+					q.samples = q.samples[1:]
+				}
+				r.observeQueued(q.sink)
+			} else {
+				q.lastErr = err
+			}
+			r.mu.Unlock()
+			if err == nil {
+				backoff = 0
+				continue
+			}
+
+			backoff = min(max(2*backoff, retryMinBackoff), retryMaxBackoff)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-q.done:
+				timer.Stop()
+				return
+			}
+		}
+	}
+}
+
+// expire drops the samples of q older than the max age. r.mu must be held.
+func (r *retryQueues) expire(q *retryQueue, now time.Time) {
+	if r.config.MaxAge == 0 {
+		return
+	}
+	expired := 0
+	for expired < len(q.samples) && now.Sub(q.samples[expired].Time) > r.config.MaxAge {
+		expired++
+	}
+	r.drop(q.sink, dropReasonExpired, q.lastErr, q.samples[:expired])
+	q.samples = q.samples[expired:]
+}
+
+// retain stops the queues of the sinks not in sinks, waiting for a send in
+// progress, so the sinks can be closed. Their samples are dropped.
+func (r *retryQueues) retain(sinks []sink) {
+	if r == nil {
+		return
+	}
+	open := make(map[sink]bool, len(sinks))
+	for _, sk := range sinks {
+		open[sk] = true
+	}
+	r.mu.Lock()
+	stale := make([]*retryQueue, 0)
+	for sk, q := range r.queues {
+		if !open[sk] {
+			stale = append(stale, q)
+			delete(r.queues, sk)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, q := range stale {
+		close(q.done)
+		<-q.stopped
+		r.mu.Lock()
+		r.drop(q.sink, dropReasonClosed, q.lastErr, q.samples)
+		q.samples = nil
+		r.observeQueued(q.sink)
+		r.mu.Unlock()
+	}
+}
+
+// observeQueued sets the gauge of the samples waiting for the sinks named
+// as sk. r.mu must be held.
+func (r *retryQueues) observeQueued(sk sink) {
+	queued := 0
+	for other, q := range r.queues {
+		if other.Name() == sk.Name() {
+			queued += len(q.samples)
+		}
+	}
+	sinkRetryQueuedGauge.WithLabelValues(sk.Name()).Set(float64(queued))
+}
+
+// Stop stops every queue, dropping the samples still waiting. The samples
+// enqueued later are to be sent directly.
+func (r *retryQueues) Stop() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+	r.retain(nil)
+}
+
+// Close stops the queues and closes the dead letter log.
+func (r *retryQueues) Close() error {
+	r.Stop()
+	if r == nil || r.deadLetter == nil {
+		return nil
+	}
+	return r.deadLetter.Close()
+}
+
+// drop counts samples as dropped and writes them to the dead letter log. r.mu must be held.
+func (r *retryQueues) drop(sk sink, reason string, err error, samples []*sample) {
+	if len(samples) == 0 {
+		return
+	}
+	sinkDroppedCounter.WithLabelValues(sk.Name(), reason).Add(float64(len(samples)))
+	if r.deadLetter == nil {
+		return
+	}
+	for _, s := range samples {
+		if err := r.deadLetter.Write(sk.Name(), reason, err, s); err != nil {
+			slog.Error("An error occured on write dead letter", "sink", sk.Name(), "err", err)
+			return
+		}
+	}
+}
+
+// deadLetter is a line of the dead letter log.
+type deadLetter struct {
+	Sink   string  `json:"sink"`
+	Reason string  `json:"reason"`
+	Error  string  `json:"error,omitempty"`
+	Sample *sample `json:"sample"`
+}
+
+// deadLetterLog appends dead letters to a file bounded in size.
+type deadLetterLog struct {
+	path string
+	max  int64
+	file *os.File
+	size int64
+}
+
+func openDeadLetterLog(path string, max int64) (*deadLetterLog, error) {
+	l := &deadLetterLog{
+		path: path,
+		max:  max,
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *deadLetterLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.file = f
+	l.size = fi.Size()
+	return nil
+}
+
+func (l *deadLetterLog) Write(name, reason string, sendErr error, s *sample) error {
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+	if l.size >= l.max {
+		l.file.Close()
+		l.file = nil
+		// Reopen the path even when the rename failed, to keep appending to
+		// the full file rather than losing the dead letters.
+		if err := os.Rename(l.path, l.path+".1"); err != nil {
+			slog.Warn("An error occured on rotate dead letter", "path", l.path, "err", err)
+		}
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+
+	d := &deadLetter{
+		Sink:   name,
+		Reason: reason,
+		Sample: s,
+	}
+	if sendErr != nil {
+		d.Error = sendErr.Error()
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	n, err := l.file.Write(append(b, '\n'))
+	l.size += int64(n)
+	return err
+}
+
+func (l *deadLetterLog) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	sacnPort       = 5568
+	sacnSlots      = 512
+	sacnPacketSize = 126 + sacnSlots
+	sacnSourceName = "twitter-calorie"
+)
+
+type SACNSinkParam struct {
+	// Destination is a unicast host. Empty means the universe's multicast group.
+	Destination string `yaml:"destination"`
+	Universe    int    `yaml:"universe"`
+	Priority    int    `yaml:"priority"`
+	// Channels are 1-based DMX slots that carry the calorie, such as "1,2,10-12".
+	Channels string `yaml:"channels"`
+}
+
+func defaultSACNSinkParam() *SACNSinkParam {
+	return &SACNSinkParam{
+		Priority: 100,
+		Channels: "1",
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *SACNSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain SACNSinkParam
+	*p = *defaultSACNSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *SACNSinkParam) enabled() bool {
+	return p.Universe != 0
+}
+
+// sacnSink streams the calorie as ANSI E1.31 (sACN) DMX data.
+type sacnSink struct {
+	conn     net.Conn
+	packet   []byte
+	channels []int
+	sequence byte
+}
+
+func newSACNSink(param *SACNSinkParam) (*sacnSink, error) {
+	if param.Universe < 1 || 63999 < param.Universe {
+		return nil, fmt.Errorf("sacn universe must be in 1-63999: %d", param.Universe)
+	}
+	if param.Priority < 0 || 200 < param.Priority {
+		return nil, fmt.Errorf("sacn priority must be in 0-200: %d", param.Priority)
+	}
+	channels, err := parseChannels(param.Channels)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range channels {
+		if c < 1 || sacnSlots < c {
+			return nil, fmt.Errorf("sacn channel must be in 1-%d: %d", sacnSlots, c)
+		}
+	}
+
+	host := param.Destination
+	if host == "" {
+		host = fmt.Sprintf("239.255.%d.%d", param.Universe>>8, param.Universe&0xff)
+	}
+	conn, err := net.Dial("udp", net.JoinHostPort(host, strconv.Itoa(sacnPort)))
+	if err != nil {
+		return nil, err
+	}
+
+	var cid [16]byte
+	if _, err := rand.Read(cid[:]); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &sacnSink{
+		conn:     conn,
+		packet:   newSACNPacket(cid, byte(param.Priority), uint16(param.Universe)),
+		channels: channels,
+	}, nil
+}
+
+func (sn *sacnSink) Name() string {
+	return "sacn"
+}
+
+func (sn *sacnSink) Send(s *sample) error {
+	value := calorieToDMX(s.Calorie)
+	for _, c := range sn.channels {
+		sn.packet[125+c] = value
+	}
+	sn.packet[111] = sn.sequence
+	sn.sequence++
+
+	_, err := sn.conn.Write(sn.packet)
+	return err
+}
+
+func (sn *sacnSink) Close() error {
+	return sn.conn.Close()
+}
+
+// newSACNPacket builds a data packet with every layer header filled in,
+// leaving only the sequence number and slot values to be updated per send.
+func newSACNPacket(cid [16]byte, priority byte, universe uint16) []byte {
+	p := make([]byte, sacnPacketSize)
+
+	// Root layer
+	binary.BigEndian.PutUint16(p[0:], 0x0010)
+	copy(p[4:], "ASC-E1.17")
+	binary.BigEndian.PutUint16(p[16:], 0x7000|uint16(sacnPacketSize-16))
+	binary.BigEndian.PutUint32(p[18:], 0x00000004)
+	copy(p[22:], cid[:])
+
+	// Framing layer
+	binary.BigEndian.PutUint16(p[38:], 0x7000|uint16(sacnPacketSize-38))
+	binary.BigEndian.PutUint32(p[40:], 0x00000002)
+	copy(p[44:108], sacnSourceName)
+	p[108] = priority
+	binary.BigEndian.PutUint16(p[113:], universe)
+
+	// DMP layer
+	binary.BigEndian.PutUint16(p[115:], 0x7000|uint16(sacnPacketSize-115))
+	p[117] = 0x02
+	p[118] = 0xa1
+	binary.BigEndian.PutUint16(p[121:], 0x0001)
+	binary.BigEndian.PutUint16(p[123:], sacnSlots+1)
+
+	return p
+}
+
+func calorieToDMX(calorie int32) byte {
+	if calorie < 0 {
+		return 0
+	}
+	if 100 < calorie {
+		return 255
+	}
+	return byte(calorie * 255 / 100)
+}
+
+// parseChannels parses a list of channels such as "1,2,10-12".
+func parseChannels(v string) ([]int, error) {
+	channels := make([]int, 0)
+	if v == "" {
+		return channels, nil
+	}
+
+	for _, part := range strings.Split(v, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		from, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid channel: %s", part)
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, err = strconv.Atoi(bounds[1]); err != nil || to < from {
+				return nil, fmt.Errorf("invalid channel range: %s", part)
+			}
+		}
+		for c := from; c <= to; c++ {
+			channels = append(channels, c)
+		}
+	}
+	return channels, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	scheduleDateLayout = "2006-01-02"
+	scheduleTimeLayout = "15:04"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// scheduleConfig limits polling and sending to the windows. Without windows
+// the scale is always active.
+type scheduleConfig struct {
+	Windows []*windowConfig `yaml:"windows"`
+	// Idle is the calorie sent outside the windows.
+	Idle int32 `yaml:"idle"`
+	// Timezone is the IANA name the windows are in. Empty is the local time.
+	Timezone string `yaml:"timezone"`
+}
+
+// windowConfig is a daily time range. An end before the start ends on the next day.
+type windowConfig struct {
+	// Days are weekdays such as "fri" or dates such as "2026-10-17" the window
+	// starts on. Empty is every day.
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+type schedule struct {
+	windows  []*window
+	idle     int32
+	location *time.Location
+}
+
+type window struct {
+	weekdays map[time.Weekday]bool
+	dates    map[string]bool
+	// start and end are the times of day on the clock.
+	start time.Duration
+	end   time.Duration
+}
+
+func (c *scheduleConfig) schedule() (*schedule, error) {
+	location := time.Local
+	if c.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(c.Timezone); err != nil {
+			return nil, err
+		}
+	}
+
+	windows := make([]*window, 0, len(c.Windows))
+	for _, wc := range c.Windows {
+		w := &window{
+			weekdays: make(map[time.Weekday]bool),
+			dates:    make(map[string]bool),
+		}
+		for _, day := range wc.Days {
+			if weekday, ok := weekdays[strings.ToLower(day)]; ok {
+				w.weekdays[weekday] = true
+				continue
+			}
+			if _, err := time.Parse(scheduleDateLayout, day); err != nil {
+				return nil, fmt.Errorf("invalid schedule day: %s", day)
+			}
+			w.dates[day] = true
+		}
+
+		var err error
+		if w.start, err = parseTimeOfDay(wc.Start); err != nil {
+			return nil, err
+		}
+		if w.end, err = parseTimeOfDay(wc.End); err != nil {
+			return nil, err
+		}
+		if w.start == w.end {
+			return nil, fmt.Errorf("schedule window must not start and end at the same time: %s", wc.Start)
+		}
+		windows = append(windows, w)
+	}
+
+	return &schedule{
+		windows:  windows,
+		idle:     c.Idle,
+		location: location,
+	}, nil
+}
+
+func parseTimeOfDay(v string) (time.Duration, error) {
+	t, err := time.Parse(scheduleTimeLayout, v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schedule time: %s", v)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// active reports whether t is in any window. A nil schedule is always active.
+func (s *schedule) active(t time.Time) bool {
+	if s == nil || len(s.windows) == 0 {
+		return true
+	}
+
+	// Days changing daylight saving time aren't 24h, so read the clock.
+	t = t.In(s.location)
+	hour, minute, second := t.Clock()
+	offset := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second
+	for _, w := range s.windows {
+		if w.start <= w.end {
+			if w.on(t) && w.start <= offset && offset < w.end {
+				return true
+			}
+			continue
+		}
+		// The part after midnight belongs to the window started the day before.
+		if w.on(t) && w.start <= offset {
+			return true
+		}
+		if w.on(t.AddDate(0, 0, -1)) && offset < w.end {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *window) on(t time.Time) bool {
+	if len(w.weekdays) == 0 && len(w.dates) == 0 {
+		return true
+	}
+	return w.weekdays[t.Weekday()] || w.dates[t.Format(scheduleDateLayout)]
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	caloriev1 "github.com/miyukki/twitter-calorie/proto/calorie/v1"
+)
+
+const (
+	// sampleSchema names the message every sink sends, for consumers to pick
+	// the decoder by, e.g. from a header.
+	sampleSchema = "calorie.v1.Sample"
+
+	sampleEncodingJSON     = "json"
+	sampleEncodingProtobuf = "protobuf"
+)
+
+var sampleJSONOptions = protojson.MarshalOptions{EmitUnpopulated: true}
+
+func toProtoSample(s *sample) *caloriev1.Sample {
+	return &caloriev1.Sample{
+		Keyword:     s.Keyword,
+		Tweets:      int32(s.Tweets),
+		AvgInterval: s.AvgInterval,
+		Calorie:     s.Calorie,
+		Time:        timestamppb.New(s.Time),
+	}
+}
+
+// MarshalJSON encodes s with the JSON mapping of calorie.v1.Sample, so every
+// JSON output shares the schema.
+func (s sample) MarshalJSON() ([]byte, error) {
+	return sampleJSONOptions.Marshal(toProtoSample(&s))
+}
+
+// encodeSample encodes s as calorie.v1.Sample in the given encoding, json or protobuf.
+func encodeSample(s *sample, encoding string) ([]byte, error) {
+	switch encoding {
+	case sampleEncodingJSON:
+		return sampleJSONOptions.Marshal(toProtoSample(s))
+	case sampleEncodingProtobuf:
+		return proto.Marshal(toProtoSample(s))
+	}
+	return nil, fmt.Errorf("unknown sample encoding: %s", encoding)
+}
+
+func validateSampleEncoding(encoding string) error {
+	if encoding != sampleEncodingJSON && encoding != sampleEncodingProtobuf {
+		return fmt.Errorf("unknown sample encoding: %s", encoding)
+	}
+	return nil
+}
+
+// sampleContentType is the MIME type of the samples in encoding, with the
+// schema as a parameter.
+func sampleContentType(encoding string) string {
+	if encoding == sampleEncodingProtobuf {
+		return "application/x-protobuf; proto=" + sampleSchema
+	}
+	return "application/json; proto=" + sampleSchema
+}
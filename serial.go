@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	"go.bug.st/serial"
+	"go.yaml.in/yaml/v3"
+)
+
+type SerialSinkParam struct {
+	Device string `yaml:"device"`
+	Baud   int    `yaml:"baud"`
+	// Format is a text/template over the sample, written as one line per sample.
+	Format string `yaml:"format"`
+}
+
+func defaultSerialSinkParam() *SerialSinkParam {
+	return &SerialSinkParam{
+		Baud:   9600,
+		Format: "{{.Calorie}}",
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *SerialSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain SerialSinkParam
+	*p = *defaultSerialSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *SerialSinkParam) enabled() bool {
+	return p.Device != ""
+}
+
+// serialSink writes a line per new sample to a serial port, e.g. an Arduino
+// driving a physical gauge over USB.
+type serialSink struct {
+	port   serial.Port
+	format *template.Template
+	last   time.Time
+}
+
+func newSerialSink(param *SerialSinkParam) (*serialSink, error) {
+	format, err := template.New("serial").Parse(param.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := serial.Open(param.Device, &serial.Mode{BaudRate: param.Baud})
+	if err != nil {
+		return nil, err
+	}
+
+	return &serialSink{
+		port:   port,
+		format: format,
+	}, nil
+}
+
+func (p *serialSink) Name() string {
+	return "serial"
+}
+
+func (p *serialSink) Send(s *sample) error {
+	if p.last.Equal(s.Time) {
+		return nil
+	}
+	var line bytes.Buffer
+	if err := p.format.Execute(&line, s); err != nil {
+		return err
+	}
+	line.WriteByte('\n')
+	if _, err := p.port.Write(line.Bytes()); err != nil {
+		return err
+	}
+	p.last = s.Time
+	return nil
+}
+
+func (p *serialSink) Close() error {
+	return p.port.Close()
+}
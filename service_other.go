@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func isService() bool {
+	return false
+}
+
+func runAsService() {}
+
+func runService(args []string) bool {
+	fmt.Fprintln(os.Stderr, "service is only supported on Windows, use systemd or similar elsewhere")
+	return false
+}
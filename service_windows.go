@@ -0,0 +1,210 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const serviceStopTimeout = 30 * time.Second
+
+func isService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
+
+// runAsService runs the scale under the service control manager, logging to the event log.
+func runAsService() {
+	elog, err := eventlog.Open(serviceName)
+	if err != nil {
+		fatal("An error occured on open event log", "err", err)
+	}
+	defer elog.Close()
+	logOutput = &eventLogWriter{log: elog}
+	setupLogging(&newConfig().Log)
+
+	if err := svc.Run(serviceName, &service{}); err != nil {
+		fatal("An error occured on run service", "err", err)
+	}
+}
+
+// service translates the service control requests into the signals run handles.
+type service struct{}
+
+func (*service) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+	quit := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		run(quit)
+		close(done)
+	}()
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				quit <- os.Interrupt
+				<-done
+				return false, 0
+			}
+		case <-done:
+			// run only returns on quit, so the scale failed on its own.
+			return false, 1
+		}
+	}
+}
+
+// eventLogWriter writes each log record as an event of its level.
+type eventLogWriter struct {
+	log   *eventlog.Log
+	level slog.Level
+}
+
+func (w *eventLogWriter) SetLevel(level slog.Level) {
+	w.level = level
+}
+
+func (w *eventLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	var err error
+	switch {
+	case w.level >= slog.LevelError:
+		err = w.log.Error(1, msg)
+	case w.level >= slog.LevelWarn:
+		err = w.log.Warning(1, msg)
+	default:
+		err = w.log.Info(1, msg)
+	}
+	return len(p), err
+}
+
+// runService manages the Windows service:
+//
+//	service install [flags]   install the service running with the flags, e.g. -config C:\calorie\config.yaml
+//	service uninstall
+//	service start
+//	service stop
+func runService(args []string) bool {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: service install [flags] | uninstall | start | stop")
+		return false
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		slog.Error("An error occured on connect service manager", "err", err)
+		return false
+	}
+	defer m.Disconnect()
+
+	switch args[0] {
+	case "install":
+		err = installService(m, args[1:])
+	case "uninstall":
+		err = uninstallService(m)
+	case "start":
+		err = startService(m)
+	case "stop":
+		err = stopService(m)
+	default:
+		err = fmt.Errorf("unknown service command: %s", args[0])
+	}
+	if err != nil {
+		slog.Error("An error occured on service "+args[0], "err", err)
+		return false
+	}
+	slog.Info("Service " + args[0] + " done")
+	return true
+}
+
+func installService(m *mgr.Mgr, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.Abs(exe); err != nil {
+		return err
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceName,
+		Description: "Sends the calorie of a Twitter keyword to the show outputs.",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	// Restart after a crash, as the show must go on.
+	actions := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+	}
+	if err := s.SetRecoveryActions(actions, uint32((24 * time.Hour).Seconds())); err != nil {
+		return err
+	}
+	if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		s.Delete()
+		return err
+	}
+	return nil
+}
+
+func uninstallService(m *mgr.Mgr) error {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	return eventlog.Remove(serviceName)
+}
+
+func startService(m *mgr.Mgr) error {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Start()
+}
+
+func stopService(m *mgr.Mgr) error {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(serviceStopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for the service to stop")
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
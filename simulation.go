@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	simulationRamp     = "ramp"
+	simulationBurst    = "burst"
+	simulationSine     = "sine"
+	simulationTimeline = "timeline"
+	// simulationBurstRatio is the part of a burst period spent at the max rate.
+	simulationBurstRatio = 0.2
+	minSimulationRate    = 0.01
+)
+
+// simulationConfig generates synthetic tweets instead of searching Twitter,
+// to rehearse without Twitter access. Rates are in tweets per second.
+type simulationConfig struct {
+	// Profile is "ramp" from MinRate to MaxRate, "burst" of MaxRate over
+	// MinRate, "sine" between them, each repeating every Period, or
+	// "timeline" read from Timeline. Empty disables the simulation.
+	Profile string        `yaml:"profile"`
+	MinRate float64       `yaml:"minRate"`
+	MaxRate float64       `yaml:"maxRate"`
+	Period  time.Duration `yaml:"period"`
+	// Noise randomly scales the rate by up to this ratio either way.
+	Noise float64 `yaml:"noise"`
+	// Timeline is a file of "elapsed,rate" lines such as "30s,5". The rate
+	// is interpolated between the lines and the timeline loops at the end.
+	Timeline string `yaml:"timeline"`
+}
+
+func (c *simulationConfig) enabled() bool {
+	return c.Profile != ""
+}
+
+// timelinePoint is the rate at an elapsed time of a timeline.
+type timelinePoint struct {
+	elapsed time.Duration
+	rate    float64
+}
+
+type simulationSource struct {
+	config   simulationConfig
+	timeline []timelinePoint
+	start    time.Time
+	rand     *rand.Rand
+}
+
+func newSimulationSource(c *simulationConfig) (*simulationSource, error) {
+	s := &simulationSource{
+		config: *c,
+		start:  time.Now(),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	switch c.Profile {
+	case simulationRamp, simulationBurst, simulationSine:
+		if c.Period <= 0 {
+			return nil, fmt.Errorf("simulation period must be positive: %s", c.Period)
+		}
+	case simulationTimeline:
+		var err error
+		if s.timeline, err = loadTimeline(c.Timeline); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown simulation profile: %s", c.Profile)
+	}
+	return s, nil
+}
+
+// loadTimeline reads the points of a timeline file. Empty lines and lines
+// starting with # are skipped.
+func loadTimeline(path string) ([]timelinePoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	points := make([]timelinePoint, 0)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line %d in %s", n, path)
+		}
+		elapsed, err := time.ParseDuration(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid elapsed on line %d in %s: %w", n, path, err)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate on line %d in %s: %w", n, path, err)
+		}
+		if len(points) > 0 && elapsed <= points[len(points)-1].elapsed {
+			return nil, fmt.Errorf("elapsed must increase on line %d in %s", n, path)
+		}
+		points = append(points, timelinePoint{elapsed: elapsed, rate: rate})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no points in %s", path)
+	}
+	return points, nil
+}
+
+func (s *simulationSource) Name() string {
+	return "simulation"
+}
+
+// Recent generates tweets going back from now, each arriving at random
+// with the rate of the profile at its time.
+func (s *simulationSource) Recent(ctx context.Context, keyword string) ([]*tweet, error) {
+	tweets := make([]*tweet, 0, sourceCount)
+	t := time.Now()
+	for len(tweets) < sourceCount {
+		tweets = append(tweets, &tweet{
+			ID:   t.UnixNano(),
+			Time: t,
+			User: "simulation",
+			Text: fmt.Sprintf("simulated tweet %s", keyword),
+		})
+		rate := math.Max(minSimulationRate, s.rate(t.Sub(s.start)))
+		t = t.Add(-time.Duration(s.rand.ExpFloat64() / rate * float64(time.Second)))
+	}
+	return tweets, nil
+}
+
+// rate is the tweets per second at elapsed since the start of the simulation.
+func (s *simulationSource) rate(elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	c := s.config
+	var rate float64
+	switch c.Profile {
+	case simulationRamp:
+		phase := float64(elapsed%c.Period) / float64(c.Period)
+		rate = c.MinRate + (c.MaxRate-c.MinRate)*phase
+	case simulationBurst:
+		rate = c.MinRate
+		if float64(elapsed%c.Period) < float64(c.Period)*simulationBurstRatio {
+			rate = c.MaxRate
+		}
+	case simulationSine:
+		phase := float64(elapsed%c.Period) / float64(c.Period)
+		rate = c.MinRate + (c.MaxRate-c.MinRate)*(1-math.Cos(2*math.Pi*phase))/2
+	case simulationTimeline:
+		rate = s.timelineRate(elapsed)
+	}
+	if c.Noise > 0 {
+		rate *= 1 + c.Noise*(2*s.rand.Float64()-1)
+	}
+	return rate
+}
+
+func (s *simulationSource) timelineRate(elapsed time.Duration) float64 {
+	last := s.timeline[len(s.timeline)-1]
+	if last.elapsed > 0 {
+		elapsed %= last.elapsed
+	}
+	prev := s.timeline[0]
+	if elapsed <= prev.elapsed {
+		return prev.rate
+	}
+	for _, next := range s.timeline[1:] {
+		if elapsed <= next.elapsed {
+			p := float64(elapsed-prev.elapsed) / float64(next.elapsed-prev.elapsed)
+			return prev.rate + (next.rate-prev.rate)*p
+		}
+		prev = next
+	}
+	return last.rate
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sample is a single calculated calorie value with the data it was derived from.
+type sample struct {
+	Keyword     string    `json:"keyword"`
+	Tweets      int       `json:"tweets"`
+	AvgInterval float64   `json:"avgInterval"`
+	Calorie     int32     `json:"calorie"`
+	Time        time.Time `json:"time"`
+	// spanContext is of the calculation the sample came from, to link the sends to.
+	spanContext trace.SpanContext
+}
+
+// sink is an output destination that receives the latest sample on every send tick.
+type sink interface {
+	Name() string
+	Send(s *sample) error
+	Close() error
+}
+
+// sinkParam is the config of a sink type, which can be given any number of times.
+type sinkParam interface {
+	enabled() bool
+}
+
+// SinksParam lists the configured sinks by type.
+type SinksParam struct {
+	OSC         []*OSCSinkParam         `yaml:"osc"`
+	MIDI        []*MIDISinkParam        `yaml:"midi"`
+	SACN        []*SACNSinkParam        `yaml:"sacn"`
+	WLED        []*WLEDSinkParam        `yaml:"wled"`
+	MQTT        []*MQTTSinkParam        `yaml:"mqtt"`
+	RemoteWrite []*RemoteWriteSinkParam `yaml:"remoteWrite"`
+	Kafka       []*KafkaSinkParam       `yaml:"kafka"`
+	NATS        []*NATSSinkParam        `yaml:"nats"`
+	Redis       []*RedisSinkParam       `yaml:"redis"`
+	Serial      []*SerialSinkParam      `yaml:"serial"`
+	GPIO        []*GPIOSinkParam        `yaml:"gpio"`
+	Hue         []*HueSinkParam         `yaml:"hue"`
+	Link        []*LinkSinkParam        `yaml:"link"`
+	Tone        []*ToneSinkParam        `yaml:"tone"`
+	Webhook     []*WebhookSinkParam     `yaml:"webhook"`
+	Recorder    []*RecorderSinkParam    `yaml:"recorder"`
+	Alert       []*AlertSinkParam       `yaml:"alert"`
+}
+
+// validate checks the sinks that can be checked before they open.
+func (p *SinksParam) validate() error {
+	if err := validateMIDISinks(p.MIDI); err != nil {
+		return err
+	}
+	for _, tone := range p.Tone {
+		if err := tone.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pad makes sure every type has at least one entry for the flags to write
+// to. Padded entries are disabled until a flag enables them.
+func (p *SinksParam) pad() {
+	if len(p.OSC) == 0 {
+		osc := defaultOSCSinkParam()
+		osc.Host = ""
+		p.OSC = append(p.OSC, osc)
+	}
+	p.MIDI = padSinkParams(p.MIDI, defaultMIDISinkParam)
+	p.SACN = padSinkParams(p.SACN, defaultSACNSinkParam)
+	p.WLED = padSinkParams(p.WLED, defaultWLEDSinkParam)
+	p.MQTT = padSinkParams(p.MQTT, defaultMQTTSinkParam)
+	p.RemoteWrite = padSinkParams(p.RemoteWrite, defaultRemoteWriteSinkParam)
+	p.Kafka = padSinkParams(p.Kafka, defaultKafkaSinkParam)
+	p.NATS = padSinkParams(p.NATS, defaultNATSSinkParam)
+	p.Redis = padSinkParams(p.Redis, defaultRedisSinkParam)
+	p.Serial = padSinkParams(p.Serial, defaultSerialSinkParam)
+	p.GPIO = padSinkParams(p.GPIO, defaultGPIOSinkParam)
+	p.Hue = padSinkParams(p.Hue, defaultHueSinkParam)
+	p.Link = padSinkParams(p.Link, defaultLinkSinkParam)
+	p.Tone = padSinkParams(p.Tone, defaultToneSinkParam)
+	p.Webhook = padSinkParams(p.Webhook, defaultWebhookSinkParam)
+	p.Recorder = padSinkParams(p.Recorder, defaultRecorderSinkParam)
+	p.Alert = padSinkParams(p.Alert, defaultAlertSinkParam)
+}
+
+func padSinkParams[P sinkParam](params []P, defaults func() P) []P {
+	if len(params) == 0 {
+		return append(params, defaults())
+	}
+	return params
+}
+
+// sinkEntry is an opened sink along with the param it was opened with.
+type sinkEntry struct {
+	param sinkParam
+	sink  sink
+}
+
+// openSinks opens every enabled sink. Entries of prev opened with an equal
+// param are reused as they are, so a reload only reopens the sinks that
+// changed. The rest of prev is handed to retire, which must stop sending to
+// them and close them. On error the sinks opened so far are returned along
+// with it. With dryRun nothing is opened, and the sinks only log what they
+// would send.
+func openSinks(ctx context.Context, p *SinksParam, prev []*sinkEntry, dryRun bool, retire func([]sink)) ([]*sinkEntry, error) {
+	type pending struct {
+		name  string
+		param sinkParam
+		build func(sinkParam) (sink, error)
+		entry *sinkEntry
+	}
+	list := make([]*pending, 0)
+	add := func(name string, params []sinkParam, build func(sinkParam) (sink, error)) {
+		if dryRun {
+			build = func(sinkParam) (sink, error) {
+				return newDryRunSink(name, nil), nil
+			}
+		}
+		for _, param := range params {
+			if param.enabled() {
+				list = append(list, &pending{name: name, param: param, build: build})
+			}
+		}
+	}
+
+	add("osc", toSinkParams(p.OSC), func(param sinkParam) (sink, error) {
+		return newOSCSink(param.(*OSCSinkParam))
+	})
+	add("midi", toSinkParams(p.MIDI), func(param sinkParam) (sink, error) {
+		return newMIDISink(param.(*MIDISinkParam))
+	})
+	add("sacn", toSinkParams(p.SACN), func(param sinkParam) (sink, error) {
+		return newSACNSink(param.(*SACNSinkParam))
+	})
+	add("wled", toSinkParams(p.WLED), func(param sinkParam) (sink, error) {
+		return newWLEDSink(param.(*WLEDSinkParam))
+	})
+	add("mqtt", toSinkParams(p.MQTT), func(param sinkParam) (sink, error) {
+		return newMQTTSink(param.(*MQTTSinkParam))
+	})
+	add("remotewrite", toSinkParams(p.RemoteWrite), func(param sinkParam) (sink, error) {
+		return newRemoteWriteSink(ctx, param.(*RemoteWriteSinkParam)), nil
+	})
+	add("kafka", toSinkParams(p.Kafka), func(param sinkParam) (sink, error) {
+		return newKafkaSink(param.(*KafkaSinkParam))
+	})
+	add("nats", toSinkParams(p.NATS), func(param sinkParam) (sink, error) {
+		return newNATSSink(param.(*NATSSinkParam))
+	})
+	add("redis", toSinkParams(p.Redis), func(param sinkParam) (sink, error) {
+		return newRedisSink(param.(*RedisSinkParam))
+	})
+	add("serial", toSinkParams(p.Serial), func(param sinkParam) (sink, error) {
+		return newSerialSink(param.(*SerialSinkParam))
+	})
+	add("gpio", toSinkParams(p.GPIO), func(param sinkParam) (sink, error) {
+		return newGPIOSink(param.(*GPIOSinkParam))
+	})
+	add("hue", toSinkParams(p.Hue), func(param sinkParam) (sink, error) {
+		return newHueSink(param.(*HueSinkParam))
+	})
+	add("link", toSinkParams(p.Link), func(param sinkParam) (sink, error) {
+		return newLinkSink(param.(*LinkSinkParam))
+	})
+	add("tone", toSinkParams(p.Tone), func(param sinkParam) (sink, error) {
+		return newToneSink(param.(*ToneSinkParam))
+	})
+	add("webhook", toSinkParams(p.Webhook), func(param sinkParam) (sink, error) {
+		return newWebhookSink(param.(*WebhookSinkParam))
+	})
+	add("recorder", toSinkParams(p.Recorder), func(param sinkParam) (sink, error) {
+		return newRecorderSink(param.(*RecorderSinkParam))
+	})
+	add("alert", toSinkParams(p.Alert), func(param sinkParam) (sink, error) {
+		return newAlertSink(param.(*AlertSinkParam))
+	})
+
+	reused := make(map[*sinkEntry]bool)
+	for _, p := range list {
+		for _, e := range prev {
+			if !reused[e] && reflect.DeepEqual(e.param, p.param) {
+				reused[e] = true
+				p.entry = e
+				break
+			}
+		}
+	}
+	// Retire first, since devices and ports may be reopened by the new sinks.
+	replaced := make([]sink, 0)
+	for _, e := range prev {
+		if !reused[e] {
+			replaced = append(replaced, e.sink)
+		}
+	}
+	if len(replaced) > 0 {
+		retire(replaced)
+	}
+
+	entries := make([]*sinkEntry, 0, len(list))
+	var errs []error
+	for _, p := range list {
+		if p.entry == nil {
+			sk, err := p.build(p.param)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.name, err))
+				continue
+			}
+			p.entry = &sinkEntry{param: p.param, sink: sk}
+		}
+		entries = append(entries, p.entry)
+	}
+	return entries, errors.Join(errs...)
+}
+
+func entrySinks(entries []*sinkEntry) []sink {
+	sinks := make([]sink, 0, len(entries))
+	for _, e := range entries {
+		sinks = append(sinks, e.sink)
+	}
+	return sinks
+}
+
+func toSinkParams[P sinkParam](params []P) []sinkParam {
+	list := make([]sinkParam, 0, len(params))
+	for _, p := range params {
+		list = append(list, p)
+	}
+	return list
+}
+
+// dryRunSink logs the samples instead of sending them.
+type dryRunSink struct {
+	name string
+	// inner is closed along with the sink but never sent to.
+	inner sink
+}
+
+func newDryRunSink(name string, inner sink) *dryRunSink {
+	return &dryRunSink{
+		name:  name,
+		inner: inner,
+	}
+}
+
+func (d *dryRunSink) Name() string {
+	return d.name
+}
+
+func (d *dryRunSink) Send(s *sample) error {
+	slog.Info("Dry run send", "sink", d.name, "keyword", s.Keyword, "calorie", s.Calorie, "tweets", s.Tweets, "avgInterval", s.AvgInterval)
+	return nil
+}
+
+func (d *dryRunSink) Close() error {
+	if d.inner == nil {
+		return nil
+	}
+	return d.inner.Close()
+}
+
+func closeSinks(sinks []sink) {
+	for _, sk := range sinks {
+		if err := sk.Close(); err != nil {
+			slog.Error("An error occured on close", "sink", sk.Name(), "err", err)
+		}
+	}
+}
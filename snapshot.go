@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotConfig keeps the state of the scale in a file so that a restart
+// resumes with the last calorie instead of starting cold.
+type snapshotConfig struct {
+	// Path is the snapshot file. Empty disables snapshots.
+	Path string `yaml:"path"`
+	// MaxAge ignores older snapshots on restore. Zero restores any age.
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+// snapshot is the state of a scale. The latest sample holds the smoothed
+// calorie, and the interval history is the baseline it is scaled by. There
+// is no search position to keep since every search fetches the newest tweets.
+type snapshot struct {
+	SavedAt         time.Time `json:"savedAt"`
+	Keyword         string    `json:"keyword"`
+	Latest          *sample   `json:"latest"`
+	IntervalHistory []float64 `json:"intervalHistory"`
+}
+
+func (s *calorieScale) Snapshot() *snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &snapshot{
+		SavedAt:         time.Now(),
+		Keyword:         s.keyword,
+		Latest:          s.Latest(),
+		IntervalHistory: append([]float64(nil), s.intervalHistory...),
+	}
+}
+
+// Restore resumes from snap if it is of the current keyword, and reports whether it did.
+func (s *calorieScale) Restore(snap *snapshot) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if snap.Keyword != s.keyword {
+		return false
+	}
+	if snap.Latest != nil {
+		s.calorie.Store(snap.Latest)
+	}
+	if len(snap.IntervalHistory) > 0 {
+		s.intervalHistory = snap.IntervalHistory
+	}
+	return true
+}
+
+func loadSnapshot(path string) (*snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snap := &snapshot{}
+	return snap, json.Unmarshal(b, snap)
+}
+
+// saveSnapshot writes through a temporary file so a crash never leaves a partial snapshot.
+func saveSnapshot(path string, snap *snapshot) error {
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// restoreSnapshot restores s from the snapshot file unless it is missing, too old or of another keyword.
+func restoreSnapshot(c *snapshotConfig, s *calorieScale) {
+	snap, err := loadSnapshot(c.Path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		slog.Error("An error occured on load snapshot", "err", err)
+		return
+	}
+	if age := time.Since(snap.SavedAt); 0 < c.MaxAge && c.MaxAge < age {
+		slog.Info("Skipped old snapshot", "age", age.Round(time.Second))
+		return
+	}
+	if !s.Restore(snap) {
+		slog.Info("Skipped snapshot of another keyword", "keyword", snap.Keyword)
+		return
+	}
+	slog.Info("Restored snapshot", "savedAt", snap.SavedAt, "keyword", snap.Keyword)
+}
+
+// saveSnapshots saves the state of s every calculation until ctx is done.
+func saveSnapshots(ctx context.Context, path string, s *calorieScale) {
+	ticker := time.NewTicker(calculateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := saveSnapshot(path, s.Snapshot()); err != nil {
+				slog.Error("An error occured on save snapshot", "err", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
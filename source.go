@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	sourceCount          = 100
+	defaultTwitterAPIURL = "https://api.twitter.com"
+	twitterListPrefix    = "list:"
+	twitterUsersPrefix   = "users:"
+)
+
+// tweet is the part of a fetched tweet the scale uses.
+type tweet struct {
+	ID   int64     `json:"id"`
+	Time time.Time `json:"time"`
+	User string    `json:"user"`
+	Text string    `json:"text"`
+	// Quote is whether the tweet quotes another.
+	Quote bool `json:"quote"`
+	// SelfReply is whether the tweet replies to its own author, as in a thread.
+	SelfReply bool `json:"selfReply"`
+}
+
+// source fetches the recent tweets matching a keyword, newest first.
+type source interface {
+	Name() string
+	Recent(ctx context.Context, keyword string) ([]*tweet, error)
+}
+
+// multiRequestSource is a source making more than one rate limited request
+// for some keywords.
+type multiRequestSource interface {
+	pollRequests(keyword string) int
+}
+
+// newSource returns the simulation source when a profile is configured, or else the Twitter search.
+func newSource(ctx context.Context, c *config) (source, error) {
+	if c.Simulation.enabled() {
+		return newSimulationSource(&c.Simulation)
+	}
+	client, err := newTwitterClient(ctx, &c.Twitter)
+	if err != nil {
+		return nil, err
+	}
+	return newTwitterSource(client), nil
+}
+
+// newTwitterClient returns a client authenticated with the app-only bearer
+// token. Requests go to the configured API URL in place of the real API.
+func newTwitterClient(ctx context.Context, c *twitterConfig) (*twitter.Client, error) {
+	if c.APIURL != defaultTwitterAPIURL {
+		base, err := url.Parse(c.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+			Transport: &baseURLTransport{base: base, next: http.DefaultTransport},
+		})
+	}
+
+	config := &clientcredentials.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		TokenURL:     defaultTwitterAPIURL + "/oauth2/token",
+	}
+	return twitter.NewClient(config.Client(ctx)), nil
+}
+
+// baseURLTransport sends every request to base, keeping the path under the path of base.
+type baseURLTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t *baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.base.Scheme
+	req.URL.Host = t.base.Host
+	req.URL.Path = strings.TrimSuffix(t.base.Path, "/") + req.URL.Path
+	req.Host = t.base.Host
+	return t.next.RoundTrip(req)
+}
+
+// twitterSource fetches the recent tweets on Twitter. The keyword is a
+// search query, or selects a timeline by a prefix:
+//
+//	list:<id> or list:<owner>/<slug>   the tweets of the members of a List
+//	users:<name>,<name>,...            the tweets of the accounts together
+type twitterSource struct {
+	client *twitter.Client
+}
+
+func newTwitterSource(client *twitter.Client) *twitterSource {
+	return &twitterSource{
+		client: client,
+	}
+}
+
+func (t *twitterSource) Name() string {
+	return "twitter"
+}
+
+// pollRequests is the number of requests a poll of keyword makes, one per account for users:.
+func (t *twitterSource) pollRequests(keyword string) int {
+	if users, ok := strings.CutPrefix(keyword, twitterUsersPrefix); ok {
+		return max(len(splitUsers(users)), 1)
+	}
+	return 1
+}
+
+func (t *twitterSource) Recent(ctx context.Context, keyword string) ([]*tweet, error) {
+	if list, ok := strings.CutPrefix(keyword, twitterListPrefix); ok {
+		params := &twitter.ListsStatusesParams{Count: sourceCount, IncludeRetweets: twitter.Bool(true)}
+		if owner, slug, ok := strings.Cut(list, "/"); ok {
+			params.OwnerScreenName = strings.TrimPrefix(owner, "@")
+			params.Slug = slug
+		} else if id, err := strconv.ParseInt(list, 10, 64); err == nil {
+			params.ListID = id
+		} else {
+			return nil, fmt.Errorf("list must be an id or owner/slug: %s", list)
+		}
+		return t.fetch(ctx, "twitter.list", rateLimitLists, func() ([]twitter.Tweet, *http.Response, error) {
+			return t.client.Lists.Statuses(params)
+		})
+	}
+
+	if users, ok := strings.CutPrefix(keyword, twitterUsersPrefix); ok {
+		names := splitUsers(users)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no users in %s", keyword)
+		}
+		tweets := make([]*tweet, 0, sourceCount*len(names))
+		for _, name := range names {
+			timeline, err := t.fetch(ctx, "twitter.timeline", rateLimitTimeline, func() ([]twitter.Tweet, *http.Response, error) {
+				return t.client.Timelines.UserTimeline(&twitter.UserTimelineParams{
+					ScreenName:      name,
+					Count:           sourceCount,
+					IncludeRetweets: twitter.Bool(true),
+				})
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			tweets = append(tweets, timeline...)
+		}
+		// Merge the timelines into the newest across the accounts.
+		sort.SliceStable(tweets, func(i, j int) bool {
+			return tweets[i].Time.After(tweets[j].Time)
+		})
+		return tweets[:min(len(tweets), sourceCount)], nil
+	}
+
+	return t.fetch(ctx, "twitter.search", rateLimitSearch, func() ([]twitter.Tweet, *http.Response, error) {
+		result, resp, err := t.client.Search.Tweets(&twitter.SearchTweetParams{
+			Query:      keyword,
+			ResultType: "recent",
+			Count:      sourceCount,
+		})
+		if err != nil {
+			return nil, resp, err
+		}
+		return result.Statuses, resp, nil
+	})
+}
+
+// fetch runs request in a span named name and converts the statuses it returns.
+func (t *twitterSource) fetch(ctx context.Context, name, endpoint string, request func() ([]twitter.Tweet, *http.Response, error)) ([]*tweet, error) {
+	_, span := tracer.Start(ctx, name)
+	statuses, resp, err := request()
+	observeRateLimit(endpoint, resp)
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return nil, err
+	}
+	span.End()
+
+	_, span = tracer.Start(ctx, "twitter.parse", trace.WithAttributes(attribute.Int("tweets", len(statuses))))
+	defer span.End()
+	tweets := make([]*tweet, 0, len(statuses))
+	for _, status := range statuses {
+		createdAt, err := status.CreatedAtTime()
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_at %s: %w", status.CreatedAt, err)
+		}
+		tw := &tweet{ID: status.ID, Time: createdAt, Text: status.Text, Quote: status.QuotedStatusID != 0}
+		if status.User != nil {
+			tw.User = status.User.ScreenName
+			tw.SelfReply = status.InReplyToUserID != 0 && status.InReplyToUserID == status.User.ID
+		}
+		tweets = append(tweets, tw)
+	}
+	return tweets, nil
+}
+
+// rateLimitEndpoint is the endpoint polling keyword is limited by.
+func rateLimitEndpoint(keyword string) string {
+	switch {
+	case strings.HasPrefix(keyword, twitterListPrefix):
+		return rateLimitLists
+	case strings.HasPrefix(keyword, twitterUsersPrefix):
+		return rateLimitTimeline
+	}
+	return rateLimitSearch
+}
+
+// splitUsers splits a comma separated list of accounts, with or without @.
+func splitUsers(users string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(users, ",") {
+		if name = strings.TrimPrefix(strings.TrimSpace(name), "@"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
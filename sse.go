@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// sseSink streams every new sample as a Server-Sent Event.
+type sseSink struct {
+	*broadcaster
+}
+
+func newSSESink() *sseSink {
+	return &sseSink{
+		broadcaster: newBroadcaster(),
+	}
+}
+
+func (e *sseSink) Name() string {
+	return "sse"
+}
+
+func (e *sseSink) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	// Keep nginx style reverse proxies from buffering the stream.
+	rw.Header().Set("X-Accel-Buffering", "no")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	c := e.subscribe()
+	defer e.unsubscribe(c)
+
+	for {
+		select {
+		case s, ok := <-c:
+			if !ok {
+				return
+			}
+			msg, err := json.Marshal(s)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(rw, "event: calorie\ndata: %s\n\n", msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
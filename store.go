@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	storePruneInterval = time.Hour
+)
+
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS samples (
+	time INTEGER NOT NULL,
+	keyword TEXT NOT NULL,
+	tweets INTEGER NOT NULL,
+	avg_interval REAL NOT NULL,
+	calorie INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS samples_keyword_time ON samples (keyword, time);
+CREATE TABLE IF NOT EXISTS polls (
+	time INTEGER NOT NULL,
+	source TEXT NOT NULL,
+	keyword TEXT NOT NULL,
+	tweets INTEGER NOT NULL,
+	oldest INTEGER,
+	newest INTEGER,
+	error TEXT
+);
+CREATE INDEX IF NOT EXISTS polls_time ON polls (time);
+`
+
+// databaseConfig is the SQLite database keeping the samples and polls.
+type databaseConfig struct {
+	// Path is the database file. Empty disables the database.
+	Path string `yaml:"path"`
+	// Retention deletes rows older than it. Zero keeps everything.
+	Retention time.Duration `yaml:"retention"`
+}
+
+// poll summarizes a single search of a source.
+type poll struct {
+	Time    time.Time
+	Source  string
+	Keyword string
+	Tweets  int
+	// Oldest and Newest are the creation times of the fetched tweets, zero without any.
+	Oldest time.Time
+	Newest time.Time
+	Err    error
+}
+
+// store persists the samples and polls for the history, baseline
+// calibration and post-event analysis. Times are kept in Unix milliseconds.
+type store struct {
+	db        *sql.DB
+	retention time.Duration
+}
+
+func openStore(ctx context.Context, c *databaseConfig) (*store, error) {
+	db, err := sql.Open("sqlite", c.Path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, so sharing a connection avoids busy errors.
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, storeSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	st := &store{
+		db:        db,
+		retention: c.Retention,
+	}
+	if st.retention > 0 {
+		go st.prune(ctx)
+	}
+	return st, nil
+}
+
+func (st *store) RecordSample(s *sample) {
+	if st == nil {
+		return
+	}
+	_, err := st.db.Exec(`INSERT INTO samples (time, keyword, tweets, avg_interval, calorie) VALUES (?, ?, ?, ?, ?)`,
+		s.Time.UnixMilli(), s.Keyword, s.Tweets, s.AvgInterval, s.Calorie)
+	if err != nil {
+		slog.Error("An error occured on store sample", "err", err)
+	}
+}
+
+func (st *store) RecordPoll(p *poll) {
+	if st == nil {
+		return
+	}
+	var oldest, newest, errText interface{}
+	if !p.Oldest.IsZero() {
+		oldest = p.Oldest.UnixMilli()
+	}
+	if !p.Newest.IsZero() {
+		newest = p.Newest.UnixMilli()
+	}
+	if p.Err != nil {
+		errText = p.Err.Error()
+	}
+	_, err := st.db.Exec(`INSERT INTO polls (time, source, keyword, tweets, oldest, newest, error) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		p.Time.UnixMilli(), p.Source, p.Keyword, p.Tweets, oldest, newest, errText)
+	if err != nil {
+		slog.Error("An error occured on store poll", "err", err)
+	}
+}
+
+// Samples returns the samples of keyword since the given time in order, or
+// of every keyword when it is empty.
+func (st *store) Samples(keyword string, since time.Time) ([]*sample, error) {
+	rows, err := st.db.Query(`SELECT time, keyword, tweets, avg_interval, calorie FROM samples
+		WHERE (? = '' OR keyword = ?) AND time >= ? ORDER BY time`, keyword, keyword, since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := make([]*sample, 0)
+	for rows.Next() {
+		s := &sample{}
+		var t int64
+		if err := rows.Scan(&t, &s.Keyword, &s.Tweets, &s.AvgInterval, &s.Calorie); err != nil {
+			return nil, err
+		}
+		s.Time = time.UnixMilli(t)
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// Baseline returns the average intervals of the last n samples of keyword,
+// oldest first, to calibrate a scale before its first search.
+func (st *store) Baseline(keyword string, n int) []float64 {
+	intervals := make([]float64, 0)
+	if st == nil {
+		return intervals
+	}
+	rows, err := st.db.Query(`SELECT avg_interval FROM (
+		SELECT time, avg_interval FROM samples WHERE keyword = ? ORDER BY time DESC LIMIT ?
+	) ORDER BY time`, keyword, n)
+	if err != nil {
+		slog.Error("An error occured on load baseline", "keyword", keyword, "err", err)
+		return intervals
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v float64
+		if err := rows.Scan(&v); err != nil {
+			slog.Error("An error occured on load baseline", "keyword", keyword, "err", err)
+			return make([]float64, 0)
+		}
+		intervals = append(intervals, v)
+	}
+	return intervals
+}
+
+// prune deletes the rows past the retention every storePruneInterval until ctx is done.
+func (st *store) prune(ctx context.Context) {
+	ticker := time.NewTicker(storePruneInterval)
+	defer ticker.Stop()
+	for {
+		before := time.Now().Add(-st.retention).UnixMilli()
+		for _, table := range []string{"samples", "polls"} {
+			if _, err := st.db.Exec(`DELETE FROM `+table+` WHERE time < ?`, before); err != nil {
+				slog.Error("An error occured on prune", "table", table, "err", err)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (st *store) Close() error {
+	if st == nil {
+		return nil
+	}
+	return st.db.Close()
+}
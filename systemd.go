@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotify sends state to systemd when it started the process with
+// Type=notify, and does nothing otherwise.
+func sdNotify(state string) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return
+	}
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		slog.Warn("An error occured on notify systemd", "state", state, "err", err)
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		slog.Warn("An error occured on notify systemd", "state", state, "err", err)
+	}
+}
+
+// sdWatchdogInterval is how often systemd expects a watchdog ping, half of
+// WatchdogSec, or zero when the watchdog is off.
+func sdWatchdogInterval() time.Duration {
+	if os.Getenv("NOTIFY_SOCKET") == "" {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// sdWatchdog pings the systemd watchdog while the calculation loop keeps
+// running, until ctx is done. Once the loop stalls for staleAfter the pings
+// stop, so systemd restarts the service.
+func sdWatchdog(ctx context.Context, s *calorieScale, staleAfter time.Duration) {
+	interval := sdWatchdogInterval()
+	if interval == 0 {
+		return
+	}
+	slog.Info("Pinging systemd watchdog", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.checkLoop(staleAfter); err != nil {
+				slog.Error("An error occured on systemd watchdog, skipping ping", "err", err)
+				continue
+			}
+			sdNotify("WATCHDOG=1")
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	crossingRise = "rise"
+	crossingFall = "fall"
+)
+
+// crossing is calorie passing a threshold between two consecutive samples.
+type crossing struct {
+	Direction string `json:"direction"`
+	Threshold int32  `json:"threshold"`
+}
+
+// detectCrossings returns the thresholds passed by going from prev to cur.
+func detectCrossings(prev int32, cur int32, thresholds []int32) []crossing {
+	crossings := make([]crossing, 0)
+	for _, t := range thresholds {
+		switch {
+		case prev <= t && t < cur:
+			crossings = append(crossings, crossing{Direction: crossingRise, Threshold: t})
+		case cur <= t && t < prev:
+			crossings = append(crossings, crossing{Direction: crossingFall, Threshold: t})
+		}
+	}
+	return crossings
+}
+
+// parseThresholds parses a comma separated list of calorie thresholds.
+func parseThresholds(v string) ([]int32, error) {
+	thresholds := make([]int32, 0)
+	for _, item := range splitList(v) {
+		t, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold: %s", item)
+		}
+		thresholds = append(thresholds, int32(t))
+	}
+	return thresholds, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	toneSampleRate = 44100
+	toneBlockSize  = toneSampleRate / 20
+	// toneSmoothing is how much of the distance to the target pitch and
+	// volume is covered per audio sample, to glide instead of clicking.
+	toneSmoothing = 0.0005
+)
+
+type ToneSinkParam struct {
+	// Command plays signed 16 bit little endian mono PCM at 44.1kHz from stdin,
+	// e.g. "aplay -q -f S16_LE -r 44100 -c 1".
+	Command string  `yaml:"command"`
+	MinFreq float64 `yaml:"minFreq"`
+	MaxFreq float64 `yaml:"maxFreq"`
+	Volume  float64 `yaml:"volume"`
+}
+
+func defaultToneSinkParam() *ToneSinkParam {
+	return &ToneSinkParam{
+		MinFreq: 220,
+		MaxFreq: 880,
+		Volume:  0.1,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *ToneSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain ToneSinkParam
+	*p = *defaultToneSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *ToneSinkParam) enabled() bool {
+	return p.Command != ""
+}
+
+func (p *ToneSinkParam) validate() error {
+	if !p.enabled() {
+		return nil
+	}
+	if len(strings.Fields(p.Command)) == 0 {
+		return fmt.Errorf("tone command must not be blank: %q", p.Command)
+	}
+	if p.MinFreq <= 0 || p.MaxFreq < p.MinFreq {
+		return fmt.Errorf("tone frequencies must be 0 < minFreq <= maxFreq: %g, %g", p.MinFreq, p.MaxFreq)
+	}
+	if p.Volume <= 0 || 1 < p.Volume {
+		return fmt.Errorf("tone volume must be in (0, 1]: %g", p.Volume)
+	}
+	return nil
+}
+
+// toneSink plays a quiet tone whose pitch and volume follow calorie, so
+// front-of-house can monitor the signal by ear.
+type toneSink struct {
+	param *ToneSinkParam
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	level atomic.Value
+	wg    sync.WaitGroup
+}
+
+func newToneSink(param *ToneSinkParam) (*toneSink, error) {
+	if err := param.validate(); err != nil {
+		return nil, err
+	}
+	args := strings.Fields(param.Command)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	t := &toneSink{
+		param: param,
+		cmd:   cmd,
+		stdin: stdin,
+	}
+	t.level.Store(float64(0))
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.play()
+	}()
+	return t, nil
+}
+
+func (t *toneSink) Name() string {
+	return "tone"
+}
+
+func (t *toneSink) Send(s *sample) error {
+	t.level.Store(math.Max(0, math.Min(1, float64(s.Calorie)/100)))
+	return nil
+}
+
+func (t *toneSink) Close() error {
+	t.stdin.Close()
+	t.wg.Wait()
+	return t.cmd.Wait()
+}
+
+// play renders the tone until the player goes away. Writes block on the
+// pipe, so the player paces the rendering.
+func (t *toneSink) play() {
+	var phase, freq, volume float64
+	freq = t.param.MinFreq
+	block := make([]byte, toneBlockSize*2)
+	for {
+		level := t.level.Load().(float64)
+		// Exponential so equal calorie steps sound like equal pitch steps.
+		targetFreq := t.param.MinFreq * math.Pow(t.param.MaxFreq/t.param.MinFreq, level)
+		targetVolume := t.param.Volume * (0.3 + 0.7*level)
+
+		for i := 0; i < toneBlockSize; i++ {
+			freq += (targetFreq - freq) * toneSmoothing
+			volume += (targetVolume - volume) * toneSmoothing
+			phase += 2 * math.Pi * freq / toneSampleRate
+			if phase > 2*math.Pi {
+				phase -= 2 * math.Pi
+			}
+			v := int16(math.Sin(phase) * volume * math.MaxInt16)
+			binary.LittleEndian.PutUint16(block[i*2:], uint16(v))
+		}
+
+		if _, err := t.stdin.Write(block); err != nil {
+			return
+		}
+	}
+}
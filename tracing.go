@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	tracerName  = "github.com/miyukki/twitter-calorie"
+	serviceName = "twitter-calorie"
+)
+
+// tracer traces the pipeline. It does nothing until setupTracing installs an exporter.
+var tracer = otel.Tracer(tracerName)
+
+// tracingConfig exports the spans of the pipeline over OTLP/HTTP.
+type tracingConfig struct {
+	// Endpoint is the host and port of the collector, e.g. localhost:4318.
+	// Empty disables tracing.
+	Endpoint string `yaml:"endpoint"`
+	// Insecure sends without TLS.
+	Insecure bool `yaml:"insecure"`
+	// SampleRatio is the ratio of poll and send cycles traced.
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// setupTracing installs the OTLP exporter as the global tracer provider and
+// returns the function flushing and stopping it.
+func setupTracing(ctx context.Context, c *tracingConfig, pipeline string) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(c.Endpoint)}
+	if c.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceName(serviceName)}
+	if pipeline != "" {
+		attrs = append(attrs, semconv.ServiceInstanceID(pipeline))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
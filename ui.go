@@ -0,0 +1,17 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// registerUI serves the web dashboard at /. It charts /api/v1/calorie and
+// calls the admin API with the token entered on the page.
+func registerUI(mux *http.ServeMux) {
+	files, _ := fs.Sub(uiFiles, "ui")
+	mux.Handle("/", http.FileServer(http.FS(files)))
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	webhookTimeout     = 10 * time.Second
+	webhookQueueSize   = 64
+	webhookMaxBackoff  = 30 * time.Second
+	webhookModeSample  = "sample"
+	webhookModeCrossed = "crossing"
+)
+
+type WebhookSinkParam struct {
+	URLs []string `yaml:"urls"`
+	// Mode is either "sample" to post every new sample or "crossing" to post
+	// only when one of the comma separated Thresholds is crossed.
+	Mode       string `yaml:"mode"`
+	Thresholds string `yaml:"thresholds"`
+	// Template renders the request body from a webhookEvent. Empty posts the event as JSON.
+	Template string `yaml:"template"`
+	// Secret signs the body with HMAC-SHA256 in the X-Signature-256 header.
+	Secret  string `yaml:"secret"`
+	Retries int    `yaml:"retries"`
+}
+
+func defaultWebhookSinkParam() *WebhookSinkParam {
+	return &WebhookSinkParam{
+		Mode:       webhookModeSample,
+		Thresholds: "50,80",
+		Retries:    3,
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *WebhookSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain WebhookSinkParam
+	*p = *defaultWebhookSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *WebhookSinkParam) enabled() bool {
+	return len(p.URLs) != 0
+}
+
+// webhookEvent is posted as JSON, with the sample as calorie.v1.Sample.
+type webhookEvent struct {
+	Sample   *sample   `json:"sample"`
+	Crossing *crossing `json:"crossing,omitempty"`
+}
+
+// webhookSink posts samples or threshold crossings to webhook URLs. Posting
+// happens on a worker so slow endpoints don't hold back the other sinks.
+type webhookSink struct {
+	param      *WebhookSinkParam
+	thresholds []int32
+	client     *http.Client
+	template   *template.Template
+	queue      chan []byte
+	last       *sample
+	wg         sync.WaitGroup
+	// closing is closed by Close to stop retrying, and ctx is canceled once
+	// the queued events had their time to be posted.
+	closing chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func newWebhookSink(param *WebhookSinkParam) (*webhookSink, error) {
+	if param.Mode != webhookModeSample && param.Mode != webhookModeCrossed {
+		return nil, fmt.Errorf("unknown webhook mode: %s", param.Mode)
+	}
+
+	thresholds, err := parseThresholds(param.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &webhookSink{
+		param:      param,
+		thresholds: thresholds,
+		client:     &http.Client{Timeout: webhookTimeout},
+		queue:      make(chan []byte, webhookQueueSize),
+	}
+	w.closing = make(chan struct{})
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	if param.Template != "" {
+		t, err := template.New("webhook").Parse(param.Template)
+		if err != nil {
+			return nil, err
+		}
+		w.template = t
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for body := range w.queue {
+			for _, url := range w.param.URLs {
+				w.deliver(url, body)
+			}
+		}
+	}()
+	return w, nil
+}
+
+func (w *webhookSink) Name() string {
+	return "webhook"
+}
+
+func (w *webhookSink) Send(s *sample) error {
+	if w.last != nil && w.last.Time.Equal(s.Time) {
+		return nil
+	}
+	prev := w.last
+
+	events := make([]*webhookEvent, 0)
+	switch w.param.Mode {
+	case webhookModeSample:
+		events = append(events, &webhookEvent{Sample: s})
+	case webhookModeCrossed:
+		if prev == nil {
+			w.last = s
+			return nil
+		}
+		for _, c := range detectCrossings(prev.Calorie, s.Calorie, w.thresholds) {
+			c := c
+			events = append(events, &webhookEvent{Sample: s, Crossing: &c})
+		}
+	}
+
+	bodies := make([][]byte, 0, len(events))
+	for _, e := range events {
+		body, err := w.render(e)
+		if err != nil {
+			return err
+		}
+		bodies = append(bodies, body)
+	}
+	// Queue all of the events or none, so a retry doesn't post some twice.
+	// Send is the only writer, so the room can't shrink meanwhile.
+	if cap(w.queue)-len(w.queue) < len(bodies) {
+		return fmt.Errorf("webhook queue is full, dropped %d events", len(bodies))
+	}
+	for _, body := range bodies {
+		w.queue <- body
+	}
+	w.last = s
+	return nil
+}
+
+// Close posts the queued events once more without retrying for up to
+// webhookTimeout, then aborts the rest.
+func (w *webhookSink) Close() error {
+	close(w.closing)
+	close(w.queue)
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(webhookTimeout):
+		w.cancel()
+		<-done
+	}
+	w.cancel()
+	return nil
+}
+
+func (w *webhookSink) render(e *webhookEvent) ([]byte, error) {
+	if w.template == nil {
+		return json.Marshal(e)
+	}
+	var body bytes.Buffer
+	if err := w.template.Execute(&body, e); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+func (w *webhookSink) deliver(url string, body []byte) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(url, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= w.param.Retries || w.isClosing() {
+			sinkSendFailuresCounter.WithLabelValues(w.Name()).Inc()
+			slog.Error("An error occured on post webhook", "url", url, "err", err)
+			return
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-w.closing:
+			timer.Stop()
+		}
+		if backoff *= 2; backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+	}
+}
+
+func (w *webhookSink) isClosing() bool {
+	select {
+	case <-w.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// post sends a single request, reporting whether a failure is worth retrying.
+func (w *webhookSink) post(url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.param.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.param.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return false, nil
+	case resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
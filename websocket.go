@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout = 5 * time.Second
+)
+
+// websocketSink pushes every new sample as JSON to all connected browsers.
+type websocketSink struct {
+	*broadcaster
+	upgrader websocket.Upgrader
+}
+
+func newWebSocketSink() *websocketSink {
+	return &websocketSink{
+		broadcaster: newBroadcaster(),
+		upgrader: websocket.Upgrader{
+			// Overlays are served from anywhere, e.g. OBS browser sources.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (w *websocketSink) Name() string {
+	return "websocket"
+}
+
+func (w *websocketSink) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	conn, err := w.upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		slog.Warn("An error occured on websocket upgrade", "err", err)
+		return
+	}
+	defer conn.Close()
+
+	c := w.subscribe()
+	defer w.unsubscribe(c)
+
+	// Read only to notice the client going away.
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				w.unsubscribe(c)
+				return
+			}
+		}
+	}()
+
+	for s := range c {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := conn.WriteJSON(s); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import "fmt"
+
+// weightsConfig is how much kinds of tweets count toward the rate, from 0 to
+// exclude them, through fractions to reduce them, to 1 for a full tweet.
+type weightsConfig struct {
+	Quote float64 `yaml:"quote"`
+	// SelfReply is a reply of an author to themselves, as in a thread.
+	SelfReply float64 `yaml:"selfReply"`
+}
+
+func (c *weightsConfig) validate() error {
+	if c.Quote < 0 || 1 < c.Quote {
+		return fmt.Errorf("quote weight must be in 0-1: %f", c.Quote)
+	}
+	if c.SelfReply < 0 || 1 < c.SelfReply {
+		return fmt.Errorf("self reply weight must be in 0-1: %f", c.SelfReply)
+	}
+	return nil
+}
+
+// weight returns how much tw counts. A quote in a thread takes the lower weight.
+func (c *weightsConfig) weight(tw *tweet) float64 {
+	w := 1.0
+	if tw.Quote {
+		w = min(w, c.Quote)
+	}
+	if tw.SelfReply {
+		w = min(w, c.SelfReply)
+	}
+	return w
+}
+
+// averageInterval returns the average seconds between tweets, newest first,
+// where each tweet counts by its weight, and the number of tweets counted.
+// Excluded tweets are skipped, so the interval spans over them. It is
+// false with fewer than two tweets counted.
+func averageInterval(tweets []*tweet, weights *weightsConfig) (float64, int, bool) {
+	counted := make([]*tweet, 0, len(tweets))
+	for _, tw := range tweets {
+		if weights.weight(tw) > 0 {
+			counted = append(counted, tw)
+		}
+	}
+	if len(counted) < 2 {
+		return 0, len(counted), false
+	}
+
+	var sum, total float64
+	for i := len(counted) - 2; 0 <= i; i-- {
+		diff := counted[i].Time.Sub(counted[i+1].Time).Seconds()
+		if diff < 0 {
+			diff = 0
+		}
+
+		sum += diff
+		// The interval ends with the newer tweet, which it counts for.
+		total += weights.weight(counted[i])
+	}
+	return sum / total, len(counted), true
+}
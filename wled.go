@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	wledPort = 21324
+	// wledProtocolDNRGB addresses LEDs from a start index so strips longer
+	// than a single packet can be split into chunks.
+	wledProtocolDNRGB = 4
+	wledMaxPixels     = 489
+	// wledTimeout is how many seconds WLED waits before returning to its own
+	// effects after the last realtime packet.
+	wledTimeout = 5
+)
+
+const (
+	wledModeBrightness = "brightness"
+	wledModeHue        = "hue"
+	wledModeCount      = "count"
+)
+
+type WLEDSinkParam struct {
+	Host   string `yaml:"host"`
+	Pixels int    `yaml:"pixels"`
+	Mode   string `yaml:"mode"`
+	// Color is the hex base color used by brightness and count modes.
+	Color string `yaml:"color"`
+}
+
+func defaultWLEDSinkParam() *WLEDSinkParam {
+	return &WLEDSinkParam{
+		Pixels: 30,
+		Mode:   wledModeBrightness,
+		Color:  "ffffff",
+	}
+}
+
+// UnmarshalYAML fills the fields missing in the config file with their defaults.
+func (p *WLEDSinkParam) UnmarshalYAML(n *yaml.Node) error {
+	type plain WLEDSinkParam
+	*p = *defaultWLEDSinkParam()
+	return n.Decode((*plain)(p))
+}
+
+func (p *WLEDSinkParam) enabled() bool {
+	return p.Host != ""
+}
+
+// wledSink drives a WLED strip directly over its UDP realtime protocol.
+type wledSink struct {
+	conn   net.Conn
+	pixels int
+	mode   string
+	color  [3]byte
+}
+
+func newWLEDSink(param *WLEDSinkParam) (*wledSink, error) {
+	if param.Pixels < 1 {
+		return nil, fmt.Errorf("wled pixels must be positive: %d", param.Pixels)
+	}
+	switch param.Mode {
+	case wledModeBrightness, wledModeHue, wledModeCount:
+	default:
+		return nil, fmt.Errorf("unknown wled mode: %s", param.Mode)
+	}
+
+	color, err := parseColor(param.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.Dial("udp", net.JoinHostPort(param.Host, strconv.Itoa(wledPort)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &wledSink{
+		conn:   conn,
+		pixels: param.Pixels,
+		mode:   param.Mode,
+		color:  color,
+	}, nil
+}
+
+func (w *wledSink) Name() string {
+	return "wled"
+}
+
+func (w *wledSink) Send(s *sample) error {
+	frame := w.frame(math.Max(0, math.Min(1, float64(s.Calorie)/100)))
+	for start := 0; start < w.pixels; start += wledMaxPixels {
+		end := start + wledMaxPixels
+		if w.pixels < end {
+			end = w.pixels
+		}
+
+		packet := make([]byte, 4, 4+(end-start)*3)
+		packet[0] = wledProtocolDNRGB
+		packet[1] = wledTimeout
+		binary.BigEndian.PutUint16(packet[2:], uint16(start))
+		packet = append(packet, frame[start*3:end*3]...)
+		if _, err := w.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wledSink) Close() error {
+	return w.conn.Close()
+}
+
+// frame renders the RGB values of every pixel for the level in 0-1.
+func (w *wledSink) frame(level float64) []byte {
+	frame := make([]byte, w.pixels*3)
+	switch w.mode {
+	case wledModeBrightness:
+		for i := 0; i < w.pixels; i++ {
+			for c := 0; c < 3; c++ {
+				frame[i*3+c] = byte(float64(w.color[c]) * level)
+			}
+		}
+	case wledModeHue:
+		// From blue when calm to red when hot.
+		r, g, b := hueToRGB(240 * (1 - level))
+		for i := 0; i < w.pixels; i++ {
+			frame[i*3], frame[i*3+1], frame[i*3+2] = r, g, b
+		}
+	case wledModeCount:
+		lit := int(math.Round(float64(w.pixels) * level))
+		for i := 0; i < lit; i++ {
+			copy(frame[i*3:], w.color[:])
+		}
+	}
+	return frame
+}
+
+// hueToRGB converts a hue in degrees to a fully saturated RGB color.
+func hueToRGB(h float64) (byte, byte, byte) {
+	x := 1 - math.Abs(math.Mod(h/60, 2)-1)
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = 1, x, 0
+	case h < 120:
+		r, g, b = x, 1, 0
+	case h < 180:
+		r, g, b = 0, 1, x
+	case h < 240:
+		r, g, b = 0, x, 1
+	case h < 300:
+		r, g, b = x, 0, 1
+	default:
+		r, g, b = 1, 0, x
+	}
+	return byte(r * 255), byte(g * 255), byte(b * 255)
+}
+
+// parseColor parses a hex color such as "ff8000".
+func parseColor(v string) ([3]byte, error) {
+	var c [3]byte
+	n, err := strconv.ParseUint(v, 16, 32)
+	if err != nil || len(v) != 6 {
+		return c, fmt.Errorf("invalid color: %s", v)
+	}
+	c[0], c[1], c[2] = byte(n>>16), byte(n>>8), byte(n)
+	return c, nil
+}